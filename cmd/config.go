@@ -39,6 +39,22 @@ GOSH_AUTH_TYPE=ssh
 GOSH_AUTH_PRIVATE_KEY_FILE=~/.ssh/id_rsa
 GOSH_AUTH_PRIVATE_KEY_PASS=your-private-key-pass-base64-encoded
 
+1.3) Commit signing
+Commits are signed when a signing type is set, use an SSH private key or an armored OpenPGP private key. The allowed
+signers file is used by 'gosh verify commits', see its help for the format
+1.3.1) In config files
+Auth:
+  Signing:
+    Type: ssh|openpgp
+    Key_File: ~/.ssh/id_ed25519
+    Key_Pass: your-key-pass-base64-encoded
+    Allowed_Signers_File: ~/.gosh/allowed_signers
+1.3.2) Using ENV
+GOSH_AUTH_SIGNING_TYPE=ssh|openpgp
+GOSH_AUTH_SIGNING_KEY_FILE=~/.ssh/id_ed25519
+GOSH_AUTH_SIGNING_KEY_PASS=your-key-pass-base64-encoded
+GOSH_AUTH_SIGNING_ALLOWED_SIGNERS_FILE=~/.gosh/allowed_signers
+
 2) Output configuration
 You can set some configuration that is used by commands that output lists (like list versions and list artifacts)
 Suffixes are optional, and default to an empty string, output format can also be specified as a command flag, if set
@@ -68,6 +84,83 @@ ArtifactRepositories:
 
 You can add as many as you want/need
 
+4) Stage pipeline
+Declare the order versions have to go through the stages, a version can then only be set in a stage (using update version
+or promote) when the previous stage has the same version. Use --force to override this, which is recorded in the commit message
+4.1) In config files
+Stages:
+  Pipeline:
+    - tested
+    - published
+    - released
+4.2) Using ENV
+GOSH_STAGES_PIPELINE=tested,published,released
+
+5) Releases
+The git tag created when finalizing a release, and used to checkout a release, is rendered from a Go template
+with the fields .Type (product|hotfix) and .Name
+5.1) In config files
+Releases:
+  Tag_Format: "{{.Type}}/{{.Name}}" # default, yields e.g. product/2021.R1
+5.2) Using ENV
+GOSH_RELEASES_TAG_FORMAT="{{.Type}}/{{.Name}}"
+
+6) Version schemes
+'update version' refuses to downgrade an app unless --allow-downgrade is specified, versions are compared using
+the version scheme of the app: semver (default), calendar (e.g. 2021.10.1 or 2021.R2) or free-form (never compared).
+Versions that do not follow the scheme, e.g. 'latest', are not compared.
+Apps set their scheme with the 'version_scheme' property in their app file, this sets the default for all other apps
+6.1) In config files
+Versions:
+  Scheme: semver
+6.2) Using ENV
+GOSH_VERSIONS_SCHEME=semver
+
+7) Commits
+Commits are made by the committer gosh <gosh@github.com>, the author is the user in your git config. Both can be
+configured so the audit trail shows who or which pipeline made a change. Commit messages name the change, e.g.
+'chore(gosh): update my-app to 1.2.3 in stage dev', use a Go template to change them with the fields .Summary, .App,
+.Version, .FromVersion, .Stage, .Release, .Target and .BuildUrl. The build URL is detected on Jenkins, GitLab CI and
+GitHub Actions. A message given with --message is used as is, the Gosh-* trailers describing the change are always added
+7.1) In config files
+Commits:
+  Author:
+    Name: release-pipeline
+    Email: release-pipeline@your.company
+  Committer:
+    Name: gosh
+    Email: gosh@github.com
+  Message_Template: "deploy({{.Stage}}): {{.App}} {{.Version}}"
+  Build_Url: https://your.ci/builds/42
+7.2) Using ENV
+GOSH_COMMITS_AUTHOR_NAME=release-pipeline
+GOSH_COMMITS_AUTHOR_EMAIL=release-pipeline@your.company
+GOSH_COMMITS_COMMITTER_NAME=gosh
+GOSH_COMMITS_COMMITTER_EMAIL=gosh@github.com
+GOSH_COMMITS_MESSAGE_TEMPLATE="deploy({{.Stage}}): {{.App}} {{.Version}}"
+GOSH_COMMITS_BUILD_URL=https://your.ci/builds/42
+
+8) Deployment repository
+Gosh clones, pulls and pushes the default branch of the deployment repository. Set a branch, e.g. to run gosh against
+a staging branch, the branch is checked out on pull when the working dir is on another branch and changes are only
+committed on this branch. The --repo-branch flag overrides the configured branch.
+CI jobs can clone faster with a depth, only the last commits are cloned and fetched, version history then only covers
+these commits. A sparse clone only checks out inventory and .gosh, which is enough for all gosh commands but not to
+compile targets. Depth and sparse are used by 'init clone', which has flags for both.
+In offline mode gosh never pulls and refuses to push, changes can still be committed with --commit. The --offline flag
+enables it for a single command. Read-only commands like list, history and verify never need the remote or auth config
+8.1) In config files
+Repository:
+  Branch: staging
+  Depth: 1
+  Sparse: true
+  Offline: false
+8.2) Using ENV
+GOSH_REPOSITORY_BRANCH=staging
+GOSH_REPOSITORY_DEPTH=1
+GOSH_REPOSITORY_SPARSE=true
+GOSH_REPOSITORY_OFFLINE=false
+
 `,
 	}
 )
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"gosh/git"
 	"gosh/gitops"
 	"log"
 )
@@ -19,6 +20,9 @@ var (
 			if err := app.CreateFromTemplate(templateName); err != nil {
 				log.Fatalln("Error creating app", err)
 			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "create app " + appName + " in group " + appGroupName, Operation: git.CreateOperation, Resource: "app/" + appName, App: appName}); err != nil {
+				log.Fatalln("Error pushing changes to deployment repository", err)
+			}
 		},
 	}
 )
@@ -27,5 +31,6 @@ func init() {
 	AddGroupFlag(createAppCmd)
 	AddTemplateFlag(createAppCmd)
 	_ = createAppCmd.MarkFlagRequired(GroupFlag)
+	AddPushFlags(createAppCmd)
 	createCmd.AddCommand(createAppCmd)
 }
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	createEnvCmd = &cobra.Command{
+		Use:   "env NAME [--set KEY=VALUE]...",
+		Short: "Creates a new environment class in inventory/classes/env",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName := GetArg(args, 0)
+			env := gitops.NewEnvClass(envName)
+			if err := setEnvParameters(cmd, env); err != nil {
+				log.Fatal(err, "Invalid parameters for env class %s", envName)
+			}
+			if err := env.Create(); err != nil {
+				log.Fatal(err, "Error creating env class %s", envName)
+			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "create env class " + envName, Operation: git.CreateOperation, Resource: "env/" + envName}); err != nil {
+				log.Fatal(err, "Error pushing changes to deployment repository")
+			}
+		},
+	}
+)
+
+func init() {
+	AddSetFlag(createEnvCmd)
+	AddPushFlags(createEnvCmd)
+	createCmd.AddCommand(createEnvCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"gosh/git"
 	"gosh/gitops"
 	"gosh/log"
 )
@@ -35,6 +36,9 @@ var (
 			} else {
 				log.Fatal(err, "Error creating release %s", releaseName)
 			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "create release " + releaseName, Operation: git.CreateOperation, Resource: "release/" + releaseName, Release: releaseName}); err != nil {
+				log.Fatal(err, "Error pushing changes to deployment repository")
+			}
 		},
 	}
 )
@@ -42,5 +46,6 @@ var (
 func init() {
 	createReleaseCmd.Flags().StringP(fromStageFlag, "S", "", "--from-stage|-S STAGE")
 	createReleaseCmd.Flags().StringP(fromReleaseFlag, "R", "", "--from-release|-R PREFIX/RELEASE_NAME")
+	AddPushFlags(createReleaseCmd)
 	createCmd.AddCommand(createReleaseCmd)
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"gosh/git"
 	"gosh/gitops"
 	"log"
 )
@@ -16,10 +17,14 @@ var (
 			if err := stage.Create(); err != nil {
 				log.Fatalln("Error creating stage", stageName, err)
 			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "create stage " + stageName, Operation: git.CreateOperation, Resource: "stage/" + stageName, Stage: stageName}); err != nil {
+				log.Fatalln("Error pushing changes to deployment repository", err)
+			}
 		},
 	}
 )
 
 func init() {
+	AddPushFlags(createStageCmd)
 	createCmd.AddCommand(createStageCmd)
 }
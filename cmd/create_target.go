@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+const (
+	classFlag = "class"
+	envFlag   = "env"
+)
+
+var (
+	createTargetCmd = &cobra.Command{
+		Use:   "target NAME [--release PREFIX/RELEASE] [--stage STAGE] [--env ENV] [--group GROUP] [--class CLASS]...",
+		Short: "Creates a new target in inventory/targets, classes are added in the order: release, stage, env, group, classes",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			targetName := GetArg(args, 0)
+			target := gitops.NewTarget(targetName)
+			if releaseName := GetStringFlag(cmd, ReleaseFlag, ""); releaseName != "" {
+				if release, err := gitops.NewReleaseFromFullName(releaseName); err == nil {
+					if !release.Exists() {
+						log.Fatal(gitops.ResourceDoesNotExistErr, "Release %s does not exist", releaseName)
+					}
+					target.AddRelease(release)
+				} else {
+					log.Fatal(err, "Invalid release name %s", releaseName)
+				}
+			}
+			if stageName := GetStringFlag(cmd, StageFlag, ""); stageName != "" {
+				stage := gitops.NewStage(stageName)
+				if !stage.Exists() {
+					log.Fatal(gitops.ResourceDoesNotExistErr, "Stage %s does not exist", stageName)
+				}
+				target.AddStage(stage)
+			}
+			if envName := GetStringFlag(cmd, envFlag, ""); envName != "" {
+				env := gitops.NewEnvClass(envName)
+				if !env.Exists() {
+					log.Fatal(gitops.ResourceDoesNotExistErr, "Env class %s does not exist", envName)
+				}
+				target.AddEnvClass(env)
+			}
+			if groupName := GetStringFlag(cmd, GroupFlag, ""); groupName != "" {
+				group := gitops.NewAppGroup(groupName)
+				if !group.Exists() {
+					log.Fatal(gitops.ResourceDoesNotExistErr, "App group %s does not exist", groupName)
+				}
+				target.AddAppGroup(group)
+			}
+			if classes, err := cmd.Flags().GetStringSlice(classFlag); err == nil {
+				for _, class := range classes {
+					target.AddClass(class)
+				}
+			}
+			if err := target.Create(); err != nil {
+				log.Fatal(err, "Error creating target %s", targetName)
+			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "create target " + targetName, Operation: git.CreateOperation, Resource: "target/" + targetName, Target: targetName}); err != nil {
+				log.Fatal(err, "Error pushing changes to deployment repository")
+			}
+		},
+	}
+)
+
+func init() {
+	AddReleaseFlag(createTargetCmd)
+	AddStageFlag(createTargetCmd)
+	AddGroupFlag(createTargetCmd)
+	createTargetCmd.Flags().StringP(envFlag, "e", "", "--env|-e ENV")
+	createTargetCmd.Flags().StringSliceP(classFlag, "c", []string{}, "--class|-c CLASS (can be repeated)")
+	AddPushFlags(createTargetCmd)
+	createCmd.AddCommand(createTargetCmd)
+}
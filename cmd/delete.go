@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/log"
+)
+
+const cascadeFlag = "cascade"
+
+var (
+	deleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Deletes apps, groups, stages or releases, resources that are still referenced are only deleted with --cascade",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func AddCascadeFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool(cascadeFlag, false, "--cascade   Also remove all references to the deleted resource (default: false)")
+}
+
+// runDelete Deletes a resource using the plain or cascading delete depending on --cascade and pushes the changes
+func runDelete(cmd *cobra.Command, resourceType string, name string, del func() error, delCascade func() error) {
+	var err error
+	if GetBoolFlag(cmd, cascadeFlag, false) {
+		err = delCascade()
+	} else {
+		err = del()
+	}
+	if err != nil {
+		log.Fatal(err, "Error deleting %s %s", resourceType, name)
+	}
+	change := git.CommitInfo{Summary: "delete " + resourceType + " " + name, Operation: git.DeleteOperation, Resource: resourceType + "/" + name}
+	switch resourceType {
+	case "app":
+		change.App = name
+	case "stage":
+		change.Stage = name
+	case "release":
+		change.Release = name
+	}
+	if _, err = PushChanges(cmd, change); err != nil {
+		log.Fatal(err, "Error pushing updates to deployment repository")
+	}
+}
+
+func addDeleteCommand(cmd *cobra.Command) {
+	AddCascadeFlag(cmd)
+	AddPushFlags(cmd)
+	deleteCmd.AddCommand(cmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	deleteAppCmd = &cobra.Command{
+		Use:   "app NAME [--cascade]",
+		Short: "Deletes an app and removes it from its group, with --cascade its versions are removed from stages and releases and its class from targets",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			appName := GetArg(args, 0)
+			app, err := gitops.FindApp(appName)
+			if err != nil {
+				log.Fatal(err, "App %s does not exist", appName)
+			}
+			runDelete(cmd, "app", appName, app.Delete, app.DeleteCascade)
+		},
+	}
+)
+
+func init() {
+	addDeleteCommand(deleteAppCmd)
+}
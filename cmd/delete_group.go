@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+)
+
+var (
+	deleteGroupCmd = &cobra.Command{
+		Use:   "group NAME [--cascade]",
+		Short: "Deletes an empty app group, with --cascade all its apps are deleted as well",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			groupName := GetArg(args, 0)
+			group := gitops.NewAppGroup(groupName)
+			runDelete(cmd, "group", groupName, group.Delete, group.DeleteCascade)
+		},
+	}
+)
+
+func init() {
+	addDeleteCommand(deleteGroupCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	deleteReleaseCmd = &cobra.Command{
+		Use:   "release PREFIX/NAME [--cascade]",
+		Short: "Deletes a release, with --cascade it is removed from all targets. Stage releases are deleted with their stage",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			releaseName := GetArg(args, 0)
+			release, err := gitops.NewReleaseFromFullName(releaseName)
+			if err != nil {
+				log.Fatal(err, "Invalid release name %s", releaseName)
+			}
+			runDelete(cmd, "release", releaseName, release.Delete, release.DeleteCascade)
+		},
+	}
+)
+
+func init() {
+	addDeleteCommand(deleteReleaseCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+)
+
+var (
+	deleteStageCmd = &cobra.Command{
+		Use:   "stage NAME [--cascade]",
+		Short: "Deletes a stage and its stage release, with --cascade they are removed from all targets",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			stageName := GetArg(args, 0)
+			stage := gitops.NewStage(stageName)
+			runDelete(cmd, "stage", stageName, stage.Delete, stage.DeleteCascade)
+		},
+	}
+)
+
+func init() {
+	addDeleteCommand(deleteStageCmd)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	describeCmd = &cobra.Command{
+		Use: "describe",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+}
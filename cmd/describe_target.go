@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+const (
+	resolvedFlag = "resolved"
+	keyFlag      = "key"
+)
+
+var (
+	describeTargetCmd = &cobra.Command{
+		Use:   "target NAME [--resolved [--key KEY]]",
+		Short: "Displays the classes and parameters of a target",
+		Long: `Displays the classes and parameters of a target.
+
+With --resolved, the classes are resolved and the parameters are merged and interpolated the same way kapitan does,
+showing the final parameters tree of the target without running kapitan. Use --key to only show a single
+(dotted) parameter, e.g. --key kapitan.vars.target`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			targetName := GetArg(args, 0)
+			target := gitops.NewTarget(targetName)
+			if err := target.Read(); err != nil {
+				log.Fatal(err, "Could not read target %s", targetName)
+			}
+			var description interface{} = map[string]interface{}{
+				"name":       target.Name,
+				"classes":    target.Classes,
+				"parameters": target.Parameters,
+			}
+			if GetBoolFlag(cmd, resolvedFlag, false) {
+				inventory, err := gitops.ResolveInventory(target)
+				if err != nil {
+					log.Fatal(err, "Could not resolve inventory of target %s", targetName)
+				}
+				if key := GetStringFlag(cmd, keyFlag, ""); key != "" {
+					if value, exists := inventory.GetParameter(key); exists {
+						description = value
+					} else {
+						log.Fatal(gitops.ResourceDoesNotExistErr, "Parameter %s is not set for target %s", key, targetName)
+					}
+				} else {
+					description = map[string]interface{}{
+						"name":       inventory.Name,
+						"classes":    inventory.Classes,
+						"parameters": inventory.Parameters,
+					}
+				}
+			}
+			if data, err := yaml.Marshal(description); err == nil {
+				fmt.Print(string(data))
+			} else {
+				log.Fatal(err, "Could not describe target %s", targetName)
+			}
+		},
+	}
+)
+
+func init() {
+	describeTargetCmd.Flags().BoolP(resolvedFlag, "R", false, "--resolved|-R   Resolve classes and interpolate parameters like kapitan (default: false)")
+	describeTargetCmd.Flags().StringP(keyFlag, "k", "", "--key|-k KEY   Only show this parameter, requires --resolved")
+	describeCmd.AddCommand(describeTargetCmd)
+}
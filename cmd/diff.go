@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/log"
+	"strings"
+)
+
+const appListRefSeparator = ":"
+
+var (
+	diffCmd = &cobra.Command{
+		Use: "diff",
+	}
+	InvalidAppListRefErr = errors.New("invalid version list reference, expected stage:NAME, release:TYPE/NAME or target:NAME")
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+// LoadAppListRef Loads an AppList from a reference of the form 'stage:NAME', 'release:TYPE/NAME' or 'target:NAME'
+func LoadAppListRef(ref string) (gitops.AppList, error) {
+	parts := strings.SplitN(ref, appListRefSeparator, 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, log.Errf(InvalidAppListRefErr, "Invalid version list reference %s", ref)
+	}
+	switch parts[0] {
+	case StageFlag, ReleaseFlag, TargetFlag:
+		return LoadAppList(parts[0], parts[1])
+	}
+	return nil, log.Errf(InvalidAppListRefErr, "Unknown version list type %s in %s", parts[0], ref)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/list"
+	"gosh/log"
+)
+
+const (
+	fromFlag = "from"
+	toFlag   = "to"
+)
+
+var (
+	diffVersionsCmd = &cobra.Command{
+		Use:   "versions --from TYPE:NAME --to TYPE:NAME [FLAGS]... [APP_NAME]",
+		Short: "Shows the apps added, removed, upgraded or downgraded between two stages, releases or targets",
+		Long: `Show the apps added, removed, upgraded or downgraded between two stages, releases or targets.
+
+Version lists are referenced as stage:NAME, release:TYPE/NAME or target:NAME, e.g.
+
+gosh diff versions --from stage:tested --to release:product/2021.R2`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Tracef("running command diff versions with args: %v", args)
+			from, err := LoadAppListRef(GetStringFlag(cmd, fromFlag, ""))
+			if err != nil {
+				log.Fatal(err, "Could not load --from versions")
+			}
+			to, err := LoadAppListRef(GetStringFlag(cmd, toFlag, ""))
+			if err != nil {
+				log.Fatal(err, "Could not load --to versions")
+			}
+			diff := gitops.DiffVersions(from, to, GetStringFlag(cmd, GroupFlag, ""), GetArg(args, 0))
+			entries := make([]list.DiffEntry, 0, len(diff))
+			for _, d := range diff {
+				entries = append(entries, list.DiffEntry{Name: d.App, Change: d.Change.String(), From: d.From, To: d.To})
+			}
+			if data, err := list.RenderDiff(GetStringFlag(cmd, OutputFlag, ""), entries); err == nil {
+				fmt.Print(data)
+			} else {
+				log.Fatal(err, "Could not render versions diff")
+			}
+		},
+	}
+)
+
+func init() {
+	diffVersionsCmd.Flags().String(fromFlag, "", "--from stage:NAME|release:TYPE/NAME|target:NAME")
+	diffVersionsCmd.Flags().String(toFlag, "", "--to stage:NAME|release:TYPE/NAME|target:NAME")
+	_ = diffVersionsCmd.MarkFlagRequired(fromFlag)
+	_ = diffVersionsCmd.MarkFlagRequired(toFlag)
+	AddGroupFlag(diffVersionsCmd)
+	AddOutputFlag(diffVersionsCmd)
+	diffCmd.AddCommand(diffVersionsCmd)
+}
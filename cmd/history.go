@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+	"os"
+	"text/tabwriter"
+)
+
+const shortCommitLength = 7
+
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history {--stage STAGE | --release RELEASE} APP_NAME",
+		Short: "Shows when the version of an app changed in a stage or release, from what to what, by whom and in which commit",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Tracef("running command history with args: %v", args)
+			flag, value, err := GetMutuallyExclusiveStringFlag(cmd, StageFlag, ReleaseFlag)
+			if err == MutuallyExclusiveFlagsSetErr {
+				log.Fatal(err, "You must specify only one of --stage or --release")
+			}
+			if err == RequiredFlagNotSetErr {
+				log.Fatal(err, "You must specify --stage or --release")
+			}
+			app := GetArg(args, 0)
+			history, err := loadVersionHistory(flag, value, app)
+			if err != nil {
+				log.Fatal(err, "Could not load version history of %s", app)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "COMMIT\tDATE\tAUTHOR\tFROM\tTO")
+			for _, entry := range history {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					shortCommit(entry.Commit), entry.When.Format("2006-01-02 15:04:05"), entry.Author,
+					orNone(entry.From), orNone(entry.To))
+			}
+			_ = w.Flush()
+		},
+	}
+)
+
+func init() {
+	AddStageFlag(historyCmd)
+	AddReleaseFlag(historyCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+// loadVersionHistory Returns the version changes of an app in a stage or release, newest first
+func loadVersionHistory(appListType string, appListName string, app string) ([]gitops.VersionHistoryEntry, error) {
+	//reading the history never needs the remote, the working dir is used as it is
+	repo, err := git.OpenLocalDeploymentRepository()
+	if err != nil {
+		return nil, log.Errf(err, "Error opening working dir as Git repository")
+	}
+	switch appListType {
+	case StageFlag:
+		stage := gitops.NewStage(appListName)
+		revisions, err := loadFileRevisions(repo, stage.GetFilePath())
+		if err != nil {
+			return nil, err
+		}
+		return stage.VersionHistory(app, revisions)
+	case ReleaseFlag:
+		release, err := gitops.NewReleaseFromFullName(appListName)
+		if err != nil {
+			return nil, log.Errf(err, "Invalid release name %s", appListName)
+		}
+		revisions, err := loadFileRevisions(repo, release.GetFilePath())
+		if err != nil {
+			return nil, err
+		}
+		return release.VersionHistory(app, revisions)
+	}
+	return nil, log.Errf(gitops.ResourceDoesNotExistErr, "unknown version list type %s", appListType)
+}
+
+func loadFileRevisions(repo *git.DeploymentRepository, path string) ([]gitops.FileRevision, error) {
+	history, err := repo.FileHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]gitops.FileRevision, 0, len(history))
+	for _, r := range history {
+		revisions = append(revisions, toGitopsRevision(r))
+	}
+	return revisions, nil
+}
+
+func toGitopsRevision(r git.FileRevision) gitops.FileRevision {
+	return gitops.FileRevision{
+		Commit:   r.Hash.String(),
+		Author:   fmt.Sprintf("%s <%s>", r.Author.Name, r.Author.Email),
+		When:     r.Author.When,
+		Contents: r.Contents,
+	}
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > shortCommitLength {
+		return hash[:shortCommitLength]
+	}
+	return hash
+}
+
+func orNone(version string) string {
+	if version == "" {
+		return "-"
+	}
+	return version
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"gosh/git"
 	gosh_import "gosh/import"
 	"gosh/log"
 	"strings"
@@ -30,11 +31,15 @@ var (
 			if err := gosh_import.Import(name, apps, stages, releases, template); err != nil {
 				log.Fatal(err, "error running import with plugin %s", name)
 			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "import " + strings.ReplaceAll(argList, "|", ", ") + " with plugin " + name, Operation: git.ImportOperation}); err != nil {
+				log.Fatal(err, "Error pushing changes to deployment repository")
+			}
 		},
 	}
 )
 
 func init() {
 	AddTemplateFlag(importCmd)
+	AddPushFlags(importCmd)
 	rootCmd.AddCommand(importCmd)
 }
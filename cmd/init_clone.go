@@ -4,6 +4,12 @@ import (
 	"github.com/spf13/cobra"
 	"gosh/git"
 	"gosh/log"
+	"gosh/util"
+)
+
+const (
+	depthFlag  = "depth"
+	sparseFlag = "sparse"
 )
 
 var (
@@ -12,6 +18,12 @@ var (
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			url := GetArg(args, 0)
+			if cmd.Flags().Changed(depthFlag) {
+				util.Config.Repository.Depth, _ = cmd.Flags().GetInt(depthFlag)
+			}
+			if GetBoolFlag(cmd, sparseFlag, false) {
+				util.Config.Repository.Sparse = true
+			}
 			if _, err := git.NewDeploymentRepository(url, true); err != nil {
 				log.Fatal(err, "Error cloning deployment repository in working directory")
 			}
@@ -21,4 +33,6 @@ var (
 
 func init() {
 	initCmd.AddCommand(initCloneCmd)
+	initCloneCmd.Flags().Int(depthFlag, 0, "--depth N   Clone only the last N commits, pulls keep fetching only the latest commits (default: repository.depth config or the full history)")
+	initCloneCmd.Flags().Bool(sparseFlag, false, "--sparse   Only check out inventory and .gosh, enough for all commands but compiling targets (default: repository.sparse config)")
 }
@@ -31,16 +31,21 @@ func LoadAppList(appListType string, appListName string) (gitops.AppList, error)
 		}
 	case ReleaseFlag:
 		{
-			if release, err := gitops.NewReleaseFromFullName(appListName); err == nil {
-				if err = release.Read(); err == nil {
-					return release, nil
-				} else {
-					return nil, log.Errf(err, "Error loading release %s", appListName)
-				}
+			if release, err := loadRelease(appListName); err == nil {
+				return release, nil
 			} else {
 				return nil, err
 			}
 		}
+	case TargetFlag:
+		{
+			target := gitops.NewTarget(appListName)
+			if _, err := target.ResolveVersions(); err == nil {
+				return target, nil
+			} else {
+				return nil, log.Errf(err, "Error resolving versions of target %s", appListName)
+			}
+		}
 	default:
 		return nil, log.Errf(gitops.ResourceDoesNotExistErr, "unknown version list type %s", appListType)
 	}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	listTargetsCmd = &cobra.Command{
+		Use:   "targets",
+		Short: "Lists the names of all targets in the deployment repository",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if targets, err := gitops.ListTargets(); err == nil {
+				for _, target := range targets {
+					fmt.Println(target)
+				}
+			} else {
+				log.Fatal(err, "Could not list targets")
+			}
+		},
+	}
+)
+
+func init() {
+	listCmd.AddCommand(listTargetsCmd)
+}
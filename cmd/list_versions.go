@@ -10,16 +10,16 @@ import (
 
 var (
 	listVersionsCmd = &cobra.Command{
-		Use:  "versions {--stage STAGE | --release RELEASE} [FLAGS]... [APP_NAME]",
+		Use:  "versions {--stage STAGE | --release RELEASE | --target TARGET} [FLAGS]... [APP_NAME]",
 		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			log.Tracef("running command list versions with args: %v", args)
-			flag, value, err := GetMutuallyExclusiveStringFlag(cmd, "stage", "release")
+			flag, value, err := GetMutuallyExclusiveStringFlag(cmd, StageFlag, ReleaseFlag, TargetFlag)
 			if err == MutuallyExclusiveFlagsSetErr {
-				log.Fatal(err, "You must specify either --stage or --release, not both")
+				log.Fatal(err, "You must specify only one of --stage, --release or --target")
 			}
 			if err == RequiredFlagNotSetErr {
-				log.Fatal(err, "You must specify --stage or --release")
+				log.Fatal(err, "You must specify --stage, --release or --target")
 			}
 			if appList, err := LoadAppList(flag, value); err == nil {
 				if data, err := list.Render(
@@ -41,6 +41,7 @@ var (
 func init() {
 	AddStageFlag(listVersionsCmd)
 	AddReleaseFlag(listVersionsCmd)
+	AddTargetFlag(listVersionsCmd)
 	AddGroupFlag(listVersionsCmd)
 	AddOutputFlag(listVersionsCmd)
 	listCmd.AddCommand(listVersionsCmd)
@@ -2,16 +2,29 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
 	StageFlag    = "stage"
 	ReleaseFlag  = "release"
+	TargetFlag   = "target"
 	GroupFlag    = "group"
 	OutputFlag   = "output"
 	TemplateFlag = "template"
+	PushFlag     = "push"
+	CommitFlag   = "commit"
+	MessageFlag  = "message"
+	ForceFlag    = "force"
+	BranchFlag   = "branch"
+	ReviewFlag   = "review"
 )
 
 var RequiredFlagMissingErr = errors.New("required flag is missing")
@@ -42,6 +55,10 @@ func AddReleaseFlag(cmd *cobra.Command) {
 	cmd.Flags().StringP(ReleaseFlag, "r", "", "--release|-r RELEASE")
 }
 
+func AddTargetFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP(TargetFlag, "T", "", "--target|-T TARGET")
+}
+
 func AddGroupFlag(cmd *cobra.Command) {
 	cmd.Flags().StringP(GroupFlag, "g", "", "--group|-g GROUP")
 }
@@ -91,3 +108,155 @@ func GetBoolFlag(cmd *cobra.Command, name string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// AddForceFlag Adds --force, with -f as shorthand unless the command already uses it, e.g. for --file
+func AddForceFlag(cmd *cobra.Command) {
+	usage := "Do not enforce the stage pipeline order, only for stages, this is recorded in the commit message (default: false)"
+	if cmd.Flags().ShorthandLookup("f") != nil {
+		cmd.Flags().Bool(ForceFlag, false, "--force   "+usage)
+	} else {
+		cmd.Flags().BoolP(ForceFlag, "f", false, "--force|-f   "+usage)
+	}
+}
+
+func AddPushFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP(PushFlag, "p", false, "--push|-p   Push changes to the remote repository (default: false)")
+	cmd.Flags().Bool(CommitFlag, false, "--commit   Commit changes without pushing them, use gosh push to push them later (default: false)")
+	cmd.Flags().StringP(MessageFlag, "m", "", "--message|-m \"COMMIT MESSAGE\" (optional, only used when --push or --commit is specified)")
+	AddReviewFlags(cmd)
+}
+
+func AddReviewFlags(cmd *cobra.Command) {
+	cmd.Flags().String(BranchFlag, "", "--branch BRANCH   Push the changes to this new branch instead of the current branch, e.g. for review")
+	cmd.Flags().Bool(ReviewFlag, false, "--review   Push the changes to a new branch named after the change and print its name, the current branch is left untouched (default: false)")
+}
+
+// PushChanges Commits all changes in the working dir when --commit or --push is specified and pushes them when --push is
+// specified, returns true if changes were committed or pushed. With --branch or --review the changes are pushed to a
+// new branch instead of the current one.
+//
+// The commit message is the --message flag, or the configured commit message template rendered for the change
+func PushChanges(cmd *cobra.Command, change git.CommitInfo) (bool, error) {
+	return pushChanges(cmd, change, nil, nil)
+}
+
+// PushVersionChanges Commits and pushes like PushChanges, when the push is rejected because the remote branch changed in
+// the meantime, the working dir is reset to the remote state and replay is called to apply the version changes again
+// before retrying, replay returns the change it made on the remote state
+func PushVersionChanges(cmd *cobra.Command, change git.CommitInfo, replay func() (git.CommitInfo, error)) (bool, error) {
+	return pushChanges(cmd, change, replay, nil)
+}
+
+// PushTaggedChanges Commits and pushes like PushChanges and calls tag after committing, so the tags it creates are pushed
+// together with the commit. Changes pushed to a new branch with --branch or --review are not tagged
+func PushTaggedChanges(cmd *cobra.Command, change git.CommitInfo, tag func(repo *git.DeploymentRepository, msg string) error) (bool, error) {
+	return pushChanges(cmd, change, nil, tag)
+}
+
+func pushChanges(cmd *cobra.Command, change git.CommitInfo, replay func() (git.CommitInfo, error), tag func(repo *git.DeploymentRepository, msg string) error) (bool, error) {
+	push := GetBoolFlag(cmd, PushFlag, false)
+	branch, err := reviewBranch(cmd, change)
+	if err != nil {
+		return false, err
+	}
+	if !push && branch == "" && !GetBoolFlag(cmd, CommitFlag, false) {
+		return false, nil
+	}
+	//pulling would fail on the local changes, a rejected push is replayed on the remote state instead
+	if repo, err := git.OpenDeploymentRepository(); err == nil {
+		msg, err := commitMessage(cmd, change)
+		if err != nil {
+			return false, err
+		}
+		if branch != "" {
+			if err = pushReviewBranch(repo, msg, branch); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		if tag != nil {
+			if err = repo.Commit(msg); err != nil {
+				return false, err
+			}
+			if err = tag(repo, msg); err != nil {
+				return false, err
+			}
+		}
+		if push {
+			var replayMessage func() (string, error)
+			if replay != nil {
+				replayMessage = func() (string, error) {
+					replayed, err := replay()
+					if err != nil {
+						return "", err
+					}
+					return commitMessage(cmd, replayed)
+				}
+			}
+			err = repo.PushWithReplay(msg, replayMessage)
+		} else {
+			err = repo.Commit(msg)
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	} else {
+		return false, err
+	}
+}
+
+// reviewBranch Returns the branch to push the change to instead of the current branch, set with --branch or named after
+// the change with --review, empty when neither is specified
+func reviewBranch(cmd *cobra.Command, change git.CommitInfo) (string, error) {
+	branch := GetStringFlag(cmd, BranchFlag, "")
+	review := GetBoolFlag(cmd, ReviewFlag, false)
+	if (branch != "" || review) && GetBoolFlag(cmd, CommitFlag, false) {
+		return "", log.Errf(MutuallyExclusiveFlagsSetErr, "--branch and --review push the changes, they cannot be combined with --commit")
+	}
+	if review && branch == "" {
+		branch = git.ReviewBranchName(change, time.Now())
+	}
+	return branch, nil
+}
+
+// pushReviewBranch Pushes the changes to the branch and prints its name, so CI can open a merge request for it
+func pushReviewBranch(repo *git.DeploymentRepository, msg string, branch string) error {
+	if err := repo.PushBranch(msg, branch); err != nil {
+		return err
+	}
+	fmt.Println(branch)
+	return nil
+}
+
+// commitMessage Returns the commit message for the change, using the --message flag when it is set and recording
+// --force in the message
+func commitMessage(cmd *cobra.Command, change git.CommitInfo) (string, error) {
+	change.Message = GetStringFlag(cmd, MessageFlag, change.Message)
+	change.Forced = change.Forced || GetBoolFlag(cmd, ForceFlag, false)
+	return git.CommitMessage(change)
+}
+
+// stageVersions Reads the stage and returns its versions, used to record the versions before a change
+func stageVersions(name string) (map[string]string, error) {
+	stage := gitops.NewStage(name)
+	if err := stage.Read(); err != nil {
+		return nil, err
+	}
+	return stage.Versions, nil
+}
+
+// versionChanges Returns the version changes of the changed apps sorted by app name, previous holds the versions before
+// the change
+func versionChanges(previous map[string]string, changed map[string]string) []git.AppVersionChange {
+	apps := make([]string, 0, len(changed))
+	for appName := range changed {
+		apps = append(apps, appName)
+	}
+	sort.Strings(apps)
+	changes := make([]git.AppVersionChange, 0, len(apps))
+	for _, appName := range apps {
+		changes = append(changes, git.AppVersionChange{App: appName, From: previous[appName], To: changed[appName]})
+	}
+	return changes
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	moveCmd = &cobra.Command{
+		Use: "move",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+const toGroupFlag = "to-group"
+
+var (
+	moveAppCmd = &cobra.Command{
+		Use:   "app NAME --to-group GROUP [FLAGS]...",
+		Short: "Moves an app to another group, updating both group class lists and all targets using the app class",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			appName := GetArg(args, 0)
+			groupName := GetStringFlag(cmd, toGroupFlag, "")
+			app, err := gitops.FindApp(appName)
+			if err != nil {
+				log.Fatal(err, "App %s does not exist", appName)
+			}
+			if err = app.Move(gitops.NewAppGroup(groupName)); err != nil {
+				log.Fatal(err, "Error moving app %s to group %s", appName, groupName)
+			}
+			if _, err = PushChanges(cmd, git.CommitInfo{Summary: "move app " + appName + " to group " + groupName, Operation: git.MoveOperation, Resource: "app/" + appName, App: appName}); err != nil {
+				log.Fatal(err, "Error pushing updates to deployment repository")
+			}
+		},
+	}
+)
+
+func init() {
+	moveAppCmd.Flags().String(toGroupFlag, "", "--to-group GROUP   The group to move the app to, it is created if it does not exist")
+	_ = moveAppCmd.MarkFlagRequired(toGroupFlag)
+	AddPushFlags(moveAppCmd)
+	moveCmd.AddCommand(moveAppCmd)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+const (
+	toStageFlag = "to-stage"
+	allFlag     = "all"
+)
+
+var NothingToPromoteErr = errors.New("nothing to promote")
+
+var (
+	promoteCmd = &cobra.Command{
+		Use:   "promote {APP | --group GROUP | --all} --from-stage STAGE --to-stage STAGE",
+		Short: "Promotes app versions from one stage to another, keeping the associated stage release in sync",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			appName := GetArg(args, 0)
+			groupName := GetStringFlag(cmd, GroupFlag, "")
+			all := GetBoolFlag(cmd, allFlag, false)
+			if appName == "" && groupName == "" && !all {
+				log.Fatal(RequiredFlagNotSetErr, "You must specify an app, --group or --all")
+			}
+			if all && (appName != "" || groupName != "") {
+				log.Fatal(MutuallyExclusiveFlagsSetErr, "You cannot combine --all with an app or --group")
+			}
+			from := gitops.NewStage(GetStringFlag(cmd, fromStageFlag, ""))
+			to := gitops.NewStage(GetStringFlag(cmd, toStageFlag, ""))
+			if from.Name == to.Name {
+				log.Fatal(NothingToPromoteErr, "Source and target stage must be different")
+			}
+			force := GetBoolFlag(cmd, ForceFlag, false)
+			previous, err := stageVersions(to.Name)
+			if err != nil {
+				log.Fatal(err, "Error loading stage %s", to.Name)
+			}
+			promoted, err := to.Promote(from, groupName, appName, force)
+			if err != nil {
+				log.Fatal(err, "Error promoting versions from stage %s to stage %s", from.Name, to.Name)
+			}
+			if len(promoted) == 0 {
+				log.Infof("All versions are already up to date in stage %s", to.Name)
+				return
+			}
+			replay := func() (git.CommitInfo, error) {
+				previous, err := stageVersions(to.Name)
+				if err != nil {
+					return git.CommitInfo{}, err
+				}
+				promoted, err := gitops.NewStage(to.Name).Promote(gitops.NewStage(from.Name), groupName, appName, force)
+				return promoteCommitInfo(from, to, groupName, appName, previous, promoted), err
+			}
+			if _, err = PushVersionChanges(cmd, promoteCommitInfo(from, to, groupName, appName, previous, promoted), replay); err != nil {
+				log.Fatal(err, "Error pushing updates to deployment repository")
+			}
+		},
+	}
+)
+
+func promoteCommitInfo(from *gitops.Stage, to *gitops.Stage, groupName string, appName string, previous map[string]string, promoted map[string]string) git.CommitInfo {
+	change := git.CommitInfo{Operation: git.PromoteOperation, Stage: to.Name, Changes: versionChanges(previous, promoted)}
+	switch {
+	case appName != "":
+		change.App = appName
+		change.Version = promoted[appName]
+		change.FromVersion = previous[appName]
+		change.Summary = fmt.Sprintf("promote %s %s from stage %s to stage %s", appName, change.Version, from.Name, to.Name)
+	case groupName != "":
+		change.Summary = fmt.Sprintf("promote %d versions of group %s from stage %s to stage %s", len(promoted), groupName, from.Name, to.Name)
+	default:
+		change.Summary = fmt.Sprintf("promote %d versions from stage %s to stage %s", len(promoted), from.Name, to.Name)
+	}
+	return change
+}
+
+func init() {
+	promoteCmd.Flags().String(fromStageFlag, "", "--from-stage STAGE")
+	promoteCmd.Flags().String(toStageFlag, "", "--to-stage STAGE")
+	promoteCmd.Flags().BoolP(allFlag, "a", false, "--all|-a   Promote all apps (default: false)")
+	AddGroupFlag(promoteCmd)
+	AddForceFlag(promoteCmd)
+	AddPushFlags(promoteCmd)
+	_ = promoteCmd.MarkFlagRequired(fromStageFlag)
+	_ = promoteCmd.MarkFlagRequired(toStageFlag)
+	rootCmd.AddCommand(promoteCmd)
+}
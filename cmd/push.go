@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/log"
+)
+
+var (
+	pushCmd = &cobra.Command{
+		Use:   "push [--message \"COMMIT MESSAGE\"] [--branch BRANCH | --review]",
+		Short: "Pushes local commits, e.g. made with --commit, to the remote repository",
+		Long: `Pushes local commits, e.g. made with --commit, to the remote repository.
+
+Changes in the working dir that are not committed yet are committed first, using the commit message if given. Use
+--commit on several commands to batch them locally, review the commits and push them at once. Use --branch or --review
+to push them to a new branch for review instead, the current branch is left untouched`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			repo, err := git.OpenDeploymentRepository()
+			if err != nil {
+				log.Fatal(err, "Error opening working dir as Git repository")
+			}
+			msg, err := commitMessage(cmd, git.CommitInfo{})
+			if err != nil {
+				log.Fatal(err, "Invalid commit message")
+			}
+			branch, err := reviewBranch(cmd, git.CommitInfo{})
+			if err != nil {
+				log.Fatal(err, "Invalid branch")
+			}
+			if branch != "" {
+				err = pushReviewBranch(repo, msg, branch)
+			} else {
+				err = repo.Push(msg)
+			}
+			if err != nil {
+				log.Fatal(err, "Error pushing changes to deployment repository")
+			}
+			log.Info("Pushed changes to deployment repository")
+		},
+	}
+)
+
+func init() {
+	pushCmd.Flags().StringP(MessageFlag, "m", "", "--message|-m \"COMMIT MESSAGE\" (optional, only used for changes that are not committed yet)")
+	AddReviewFlags(pushCmd)
+	rootCmd.AddCommand(pushCmd)
+}
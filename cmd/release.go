@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	releaseCmd = &cobra.Command{
+		Use:   "release",
+		Short: "Manages the lifecycle of product and hotfix releases",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+}
+
+// loadRelease Reads the release with the given full name, e.g. 'product/2021.R1'
+func loadRelease(releaseName string) (*gitops.Release, error) {
+	if release, err := gitops.NewReleaseFromFullName(releaseName); err == nil {
+		if err = release.Read(); err == nil {
+			return release, nil
+		} else {
+			return nil, log.Errf(err, "Error loading release %s", releaseName)
+		}
+	} else {
+		return nil, err
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/list"
+	"gosh/log"
+	"gosh/util"
+	"os"
+)
+
+const (
+	tagFlag       = "tag"
+	dirFlag       = "dir"
+	artifactsFlag = "artifacts"
+)
+
+var (
+	releaseCheckoutCmd = &cobra.Command{
+		Use:   "checkout PREFIX/NAME [--tag TAG] [--dir DIR] [--artifacts] [FLAGS]... [APP_NAME]",
+		Short: "Checks out the git tag of a finalized release into a separate directory and lists its versions or artifacts",
+		Long: `Checks out the git tag of a finalized release into a separate directory and lists its versions or artifacts.
+
+The tag is determined by the configured release tag format, unless --tag is specified. The checkout is created in
+a new temporary directory unless --dir is specified, the working dir and its branch are left untouched.
+The directory is printed on stderr, so you can run other gosh commands against it using --workdir`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			releaseName := GetArg(args, 0)
+			release, err := gitops.NewReleaseFromFullName(releaseName)
+			if err != nil {
+				log.Fatal(err, "Invalid release name %s", releaseName)
+			}
+			tag := GetStringFlag(cmd, tagFlag, "")
+			if tag == "" {
+				if tag, err = release.TagName(); err != nil {
+					log.Fatal(err, "Could not determine tag name for release %s", releaseName)
+				}
+			}
+			repo, err := git.OpenLocalDeploymentRepository()
+			if err != nil {
+				log.Fatal(err, "Error opening working dir as Git repository")
+			}
+			//only tags that are not in the working dir yet are fetched from the remote
+			if !repo.HasTag(tag) {
+				if repo, err = git.OpenDeploymentRepository(); err != nil {
+					log.Fatal(err, "Error opening working dir as Git repository")
+				}
+			}
+			dir := GetStringFlag(cmd, dirFlag, "")
+			if dir == "" {
+				if dir, err = os.MkdirTemp("", "gosh-checkout-*"); err != nil {
+					log.Fatal(err, "Could not create checkout directory")
+				}
+			}
+			if err = repo.CheckoutTag(tag, dir); err != nil {
+				log.Fatal(err, "Could not checkout tag %s of release %s", tag, releaseName)
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Checked out tag %s of release %s into %s\n", tag, release.FullName(), dir)
+			util.Context.WorkingDir = dir
+			if err = release.Read(); err != nil {
+				log.Fatal(err, "Release %s does not exist in tag %s", releaseName, tag)
+			}
+			var data string
+			if GetBoolFlag(cmd, artifactsFlag, false) {
+				var artifacts map[string]string
+				artifacts, err = release.GetArtifacts(GetStringFlag(cmd, GroupFlag, ""), GetArg(args, 1), "maven")
+				if err != nil {
+					log.Fatal(err, "Could not list artifacts, make sure all apps have artifacts defined")
+				}
+				data, err = list.Render(GetStringFlag(cmd, OutputFlag, ""), artifacts, util.Config.Output.ArtifactsKeySuffix)
+			} else {
+				data, err = list.Render(
+					GetStringFlag(cmd, OutputFlag, ""),
+					release.GetVersions(GetStringFlag(cmd, GroupFlag, ""), GetArg(args, 1)),
+					util.Config.Output.VersionsKeySuffix,
+				)
+			}
+			if err != nil {
+				log.Fatal(err, "Could not list release %s", releaseName)
+			}
+			fmt.Println(data)
+		},
+	}
+)
+
+func init() {
+	releaseCheckoutCmd.Flags().String(tagFlag, "", "--tag TAG   Git tag to checkout (default: rendered from the release tag format)")
+	releaseCheckoutCmd.Flags().StringP(dirFlag, "d", "", "--dir|-d DIR   Empty directory to checkout into (default: a new temporary directory)")
+	releaseCheckoutCmd.Flags().BoolP(artifactsFlag, "A", false, "--artifacts|-A   List artifacts instead of versions (default: false)")
+	AddGroupFlag(releaseCheckoutCmd)
+	AddOutputFlag(releaseCheckoutCmd)
+	releaseCmd.AddCommand(releaseCheckoutCmd)
+}
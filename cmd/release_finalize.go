@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	releaseFinalizeCmd = &cobra.Command{
+		Use:   "finalize PREFIX/NAME [--commit|--push] [--branch BRANCH|--review]",
+		Short: "Freezes a release so its versions can no longer change, then tags it when it is committed or pushed",
+		Long: `Freezes a release so its versions can no longer change.
+
+With --commit or --push the change is committed and the commit is tagged, with --push the commit and the tag are pushed
+together. With --branch or --review the change is pushed to a new branch for review and not tagged yet, finalize the
+release again with --push once the branch is merged to tag it. Finalizing a final release again only creates the tag
+when it is missing`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			releaseName := GetArg(args, 0)
+			release, err := loadRelease(releaseName)
+			if err != nil {
+				log.Fatal(err, "Could not finalize release %s", releaseName)
+			}
+			tag, err := release.TagName()
+			if err != nil {
+				log.Fatal(err, "Could not determine tag name for release %s", releaseName)
+			}
+			if release.State == gitops.FinalRelease {
+				if repo, err := git.OpenDeploymentRepository(); err == nil {
+					if _, err = repo.ResolveTag(tag); err == nil {
+						log.Infof("Release %s is already final and tagged as %s", release.FullName(), tag)
+						return
+					}
+				} else {
+					log.Fatal(err, "Error opening working dir as Git repository")
+				}
+				log.Infof("Release %s is already final, tagging it", release.FullName())
+			} else if err = release.Finalize(); err != nil {
+				log.Fatal(err, "Could not finalize release %s", releaseName)
+			}
+			change := git.CommitInfo{Summary: "finalize release " + release.FullName(), Operation: git.FinalizeOperation, Release: release.FullName()}
+			tagged := false
+			pushed, err := PushTaggedChanges(cmd, change, func(repo *git.DeploymentRepository, msg string) error {
+				tagged = true
+				return tagRelease(repo, release, tag, msg)
+			})
+			if err != nil {
+				log.Fatal(err, "Error pushing finalized release %s to deployment repository", releaseName)
+			}
+			switch {
+			case tagged:
+				log.Infof("Finalized release %s, tagged as %s", release.FullName(), tag)
+			case pushed:
+				log.Infof("Finalized release %s for review, finalize it again with --push once it is merged to tag it", release.FullName())
+			default:
+				log.Infof("Finalized release %s in the working dir, use --commit or --push to tag it", release.FullName())
+			}
+		},
+	}
+)
+
+// tagRelease Tags the last commit that changed the release, which is the commit that finalized it
+func tagRelease(repo *git.DeploymentRepository, release *gitops.Release, tag string, msg string) error {
+	revisions, err := repo.FileHistory(release.GetFilePath())
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return log.Errf(gitops.ResourceDoesNotExistErr, "Release %s is not committed", release.FullName())
+	}
+	return repo.CreateTag(tag, msg, revisions[0].Hash)
+}
+
+func init() {
+	AddPushFlags(releaseFinalizeCmd)
+	releaseCmd.AddCommand(releaseFinalizeCmd)
+}
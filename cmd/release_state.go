@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	releaseStateCmd = &cobra.Command{
+		Use:   "state PREFIX/NAME [draft|validating|eol]",
+		Short: "Displays or changes the lifecycle state of a release, use 'release finalize' to make a release final",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			releaseName := GetArg(args, 0)
+			release, err := loadRelease(releaseName)
+			if err != nil {
+				log.Fatal(err, "Could not load release %s", releaseName)
+			}
+			stateName := GetArg(args, 1)
+			if stateName == "" {
+				fmt.Println(release.State)
+				return
+			}
+			state, err := gitops.NewReleaseState(stateName)
+			if err != nil {
+				log.Fatal(err, "Unsupported release state %s", stateName)
+			}
+			if state == gitops.FinalRelease {
+				log.Fatal(gitops.InvalidReleaseStateErr, "Use 'gosh release finalize %s' to finalize a release", releaseName)
+			}
+			if err = release.SetState(state); err != nil {
+				log.Fatal(err, "Could not change state of release %s to %s", releaseName, state)
+			}
+			if _, err = PushChanges(cmd, git.CommitInfo{Summary: fmt.Sprintf("set state of release %s to %s", release.FullName(), state), Operation: git.SetStateOperation, Release: release.FullName()}); err != nil {
+				log.Fatal(err, "Error pushing updates to deployment repository")
+			}
+		},
+	}
+)
+
+func init() {
+	AddPushFlags(releaseStateCmd)
+	releaseCmd.AddCommand(releaseStateCmd)
+}
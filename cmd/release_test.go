@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/suite"
+	"gosh/gitops"
+	"gosh/util"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ReleaseCommandsSuite struct {
+	suite.Suite
+	remote string
+}
+
+// SetupTest Creates a bare 'remote' repository with a draft release and clones it into an empty working dir
+func (suite *ReleaseCommandsSuite) SetupTest() {
+	r := suite.Require()
+	seed := filet.TmpDir(suite.T(), "")
+	seedRepo, err := git.PlainInit(seed, false)
+	r.Nil(err)
+	f := filepath.Join(seed, "inventory/classes/releases/product/R1.yml")
+	r.Nil(os.MkdirAll(filepath.Dir(f), 0755))
+	r.Nil(os.WriteFile(f, []byte("parameters:\n  R1:\n    app1:\n      version: 1.0.0\n"), 0644))
+	w, err := seedRepo.Worktree()
+	r.Nil(err)
+	r.Nil(w.AddWithOptions(&git.AddOptions{All: true}))
+	_, err = w.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@test", When: time.Now()}})
+	r.Nil(err)
+	suite.remote = filet.TmpDir(suite.T(), "")
+	_, err = git.PlainInit(suite.remote, true)
+	r.Nil(err)
+	_, err = seedRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{suite.remote}})
+	r.Nil(err)
+	r.Nil(seedRepo.Push(&git.PushOptions{}))
+
+	util.Context.WorkingDir = filet.TmpDir(suite.T(), "")
+	clone, err := git.PlainClone(util.Context.WorkingDir, false, &git.CloneOptions{URL: suite.remote})
+	r.Nil(err)
+	cfg, err := clone.Config()
+	r.Nil(err)
+	cfg.User.Name = "test"
+	cfg.User.Email = "test@test"
+	r.Nil(clone.SetConfig(cfg))
+	util.Config.Auth = util.BasicAuthConfig{}
+}
+
+func (suite *ReleaseCommandsSuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+// finalize Runs release finalize for product/R1 with the given boolean flags set
+func (suite *ReleaseCommandsSuite) finalize(flags ...string) {
+	r := suite.Require()
+	for _, flag := range flags {
+		r.Nil(releaseFinalizeCmd.Flags().Set(flag, "true"))
+	}
+	defer func() {
+		for _, flag := range flags {
+			r.Nil(releaseFinalizeCmd.Flags().Set(flag, "false"))
+		}
+	}()
+	releaseFinalizeCmd.Run(releaseFinalizeCmd, []string{"product/R1"})
+}
+
+func (suite *ReleaseCommandsSuite) remoteHead() plumbing.Hash {
+	r := suite.Require()
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	return head.Hash()
+}
+
+func (suite *ReleaseCommandsSuite) TestFinalize() {
+	r := suite.Require()
+	suite.finalize(PushFlag)
+
+	release, err := gitops.NewReleaseFromFullName("product/R1")
+	r.Nil(err)
+	r.Nil(release.Read())
+	r.Equal(gitops.FinalRelease, release.State)
+
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	tag, err := remote.Tag("product/R1")
+	r.Nil(err)
+	tagObject, err := remote.TagObject(tag.Hash())
+	r.Nil(err)
+	r.Equal(head.Hash(), tagObject.Target)
+}
+
+// TestFinalizeAgain Finalizing a final release only tags it when the tag is missing, e.g. because tagging failed before
+func (suite *ReleaseCommandsSuite) TestFinalizeAgain() {
+	r := suite.Require()
+	suite.finalize(PushFlag)
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	r.Nil(remote.DeleteTag("product/R1"))
+	local, err := git.PlainOpen(util.Context.WorkingDir)
+	r.Nil(err)
+	r.Nil(local.DeleteTag("product/R1"))
+
+	suite.finalize(PushFlag)
+	tag, err := remote.Tag("product/R1")
+	r.Nil(err)
+	tagObject, err := remote.TagObject(tag.Hash())
+	r.Nil(err)
+	r.Equal(head.Hash(), tagObject.Target)
+
+	suite.finalize(PushFlag)
+	current, err := remote.Head()
+	r.Nil(err)
+	r.Equal(head.Hash(), current.Hash())
+	retagged, err := remote.Tag("product/R1")
+	r.Nil(err)
+	r.Equal(tag.Hash(), retagged.Hash())
+}
+
+func (suite *ReleaseCommandsSuite) TestFinalizeCommit() {
+	r := suite.Require()
+	head := suite.remoteHead()
+	suite.finalize(CommitFlag)
+	local, err := git.PlainOpen(util.Context.WorkingDir)
+	r.Nil(err)
+	localHead, err := local.Head()
+	r.Nil(err)
+	tag, err := local.Tag("product/R1")
+	r.Nil(err)
+	tagObject, err := local.TagObject(tag.Hash())
+	r.Nil(err)
+	r.Equal(localHead.Hash(), tagObject.Target)
+	r.Equal(head, suite.remoteHead())
+
+	//the tag is pushed with the commit
+	pushCmd.Run(pushCmd, []string{})
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	r.Equal(localHead.Hash(), suite.remoteHead())
+	_, err = remote.Tag("product/R1")
+	r.Nil(err)
+}
+
+func (suite *ReleaseCommandsSuite) TestFinalizeReview() {
+	r := suite.Require()
+	head := suite.remoteHead()
+	r.Nil(releaseFinalizeCmd.Flags().Set(BranchFlag, "finalize-r1"))
+	defer func() { r.Nil(releaseFinalizeCmd.Flags().Set(BranchFlag, "")) }()
+	suite.finalize()
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	_, err = remote.Reference(plumbing.NewBranchReferenceName("finalize-r1"), false)
+	r.Nil(err)
+	_, err = remote.Tag("product/R1")
+	r.Equal(git.ErrTagNotFound, err)
+	r.Equal(head, suite.remoteHead())
+}
+
+// TestCheckoutWithoutRemote Checking out a tag that is in the working dir needs neither auth config nor the remote
+func (suite *ReleaseCommandsSuite) TestCheckoutWithoutRemote() {
+	r := suite.Require()
+	suite.finalize(PushFlag)
+	workingDir := util.Context.WorkingDir
+	defer func() { util.Context.WorkingDir = workingDir }()
+	util.Config.Auth = nil
+	r.Nil(os.RemoveAll(suite.remote))
+	dir := filet.TmpDir(suite.T(), "")
+	r.Nil(releaseCheckoutCmd.Flags().Set(dirFlag, dir))
+	defer func() { r.Nil(releaseCheckoutCmd.Flags().Set(dirFlag, "")) }()
+	releaseCheckoutCmd.Run(releaseCheckoutCmd, []string{"product/R1"})
+
+	release, err := gitops.NewReleaseFromFullName("product/R1")
+	r.Nil(err)
+	r.Nil(release.Read())
+	r.Equal(gitops.FinalRelease, release.State)
+}
+
+func TestReleaseCommandsTestSuite(t *testing.T) {
+	suite.Run(t, new(ReleaseCommandsSuite))
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameCmd = &cobra.Command{
+		Use: "rename",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+}
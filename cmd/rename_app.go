@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+var (
+	renameAppCmd = &cobra.Command{
+		Use:   "app OLD_NAME NEW_NAME [FLAGS]...",
+		Short: "Renames an app, updating its group and all stages, releases and targets that reference it",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			oldName := GetArg(args, 0)
+			newName := GetArg(args, 1)
+			app, err := gitops.FindApp(oldName)
+			if err != nil {
+				log.Fatal(err, "App %s does not exist", oldName)
+			}
+			if err = app.Rename(newName); err != nil {
+				log.Fatal(err, "Error renaming app %s to %s", oldName, newName)
+			}
+			if _, err = PushChanges(cmd, git.CommitInfo{Summary: "rename app " + oldName + " to " + newName, Operation: git.RenameOperation, Resource: "app/" + oldName, App: newName}); err != nil {
+				log.Fatal(err, "Error pushing updates to deployment repository")
+			}
+		},
+	}
+)
+
+func init() {
+	AddPushFlags(renameAppCmd)
+	renameCmd.AddCommand(renameAppCmd)
+}
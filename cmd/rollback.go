@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+)
+
+const (
+	stepsFlag    = "steps"
+	toCommitFlag = "to-commit"
+)
+
+var (
+	rollbackCmd = &cobra.Command{
+		Use:   "rollback --stage STAGE [--steps N | --to-commit SHA] [FLAGS]... APP_NAME",
+		Short: "Sets the version of an app in a stage back to a previous version from the git history",
+		Long: `Sets the version of an app in a stage back to a previous version from the git history.
+
+By default the version before the last version change is restored, use --steps N to go N version changes back or
+--to-commit SHA to restore the version the app had in that commit. The stage release is kept in sync, the stage
+pipeline order is not enforced since the version was deployed to the stage before`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Tracef("running command rollback with args: %v", args)
+			appName := GetArg(args, 0)
+			stage := gitops.NewStage(GetStringFlag(cmd, StageFlag, ""))
+			commit := GetStringFlag(cmd, toCommitFlag, "")
+			if commit != "" && cmd.Flags().Changed(stepsFlag) {
+				log.Fatal(MutuallyExclusiveFlagsSetErr, "You must specify only one of --steps or --to-commit")
+			}
+			repo, err := git.NewDeploymentRepository("", false)
+			if err != nil {
+				log.Fatal(err, "Error opening working dir as Git repository")
+			}
+			var version string
+			if commit != "" {
+				var revision git.FileRevision
+				if revision, err = repo.FileAt(stage.GetFilePath(), commit); err != nil {
+					log.Fatal(err, "Could not read stage %s in commit %s", stage.Name, commit)
+				}
+				version, err = stage.VersionAt(appName, toGitopsRevision(revision))
+			} else {
+				var revisions []gitops.FileRevision
+				if revisions, err = loadFileRevisions(repo, stage.GetFilePath()); err != nil {
+					log.Fatal(err, "Could not load history of stage %s", stage.Name)
+				}
+				steps, _ := cmd.Flags().GetInt(stepsFlag)
+				version, err = stage.PreviousVersion(appName, revisions, steps)
+			}
+			if err != nil {
+				log.Fatal(err, "Could not determine the version to rollback app %s to", appName)
+			}
+			previous, err := stageVersions(stage.Name)
+			if err != nil {
+				log.Fatal(err, "Error loading stage %s", stage.Name)
+			}
+			if err = stage.Rollback(appName, version); err != nil {
+				log.Fatal(err, "Error rolling back app %s to version %s in stage %s", appName, version, stage.Name)
+			}
+			log.Infof("Rolled back app %s to version %s in stage %s", appName, version, stage.Name)
+			replay := func() (git.CommitInfo, error) {
+				previous, err := stageVersions(stage.Name)
+				if err != nil {
+					return git.CommitInfo{}, err
+				}
+				return rollbackCommitInfo(stage.Name, appName, previous[appName], version), gitops.NewStage(stage.Name).Rollback(appName, version)
+			}
+			if _, err = PushVersionChanges(cmd, rollbackCommitInfo(stage.Name, appName, previous[appName], version), replay); err != nil {
+				log.Fatal(err, "Error pushing updates to deployment repository")
+			}
+		},
+	}
+)
+
+func rollbackCommitInfo(stageName string, appName string, from string, version string) git.CommitInfo {
+	return git.CommitInfo{
+		Summary:     fmt.Sprintf("rollback %s to %s in stage %s", appName, version, stageName),
+		Operation:   git.RollbackOperation,
+		App:         appName,
+		FromVersion: from,
+		Version:     version,
+		Stage:       stageName,
+	}
+}
+
+func init() {
+	AddStageFlag(rollbackCmd)
+	_ = rollbackCmd.MarkFlagRequired(StageFlag)
+	rollbackCmd.Flags().IntP(stepsFlag, "n", 1, "--steps|-n N   Number of version changes to go back (default: 1)")
+	rollbackCmd.Flags().String(toCommitFlag, "", "--to-commit SHA   Restore the version the app had in this commit")
+	AddPushFlags(rollbackCmd)
+	rootCmd.AddCommand(rollbackCmd)
+}
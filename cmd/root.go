@@ -25,9 +25,12 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable verbose mode to output logging (default: false)")
 	rootCmd.PersistentFlags().BoolP("trace", "V", false, "enable trace logging, only needed for development/testing (default: false)")
 	rootCmd.PersistentFlags().StringP("workdir", "w", "", "specify the working directory for gosh (default: $PWD)")
+	rootCmd.PersistentFlags().String("repo-branch", "", "branch of the deployment repository to clone, pull and push (default: repository.branch config or the default branch)")
+	rootCmd.PersistentFlags().Bool("offline", false, "never pull or push, use the working dir as it is without network (default: repository.offline config or false)")
 
 	cobra.OnInitialize(handleGlobalFlags)
 	cobra.OnInitialize(util.InitializeConfig)
+	cobra.OnInitialize(handleConfigFlags)
 }
 
 func Execute() error {
@@ -52,3 +55,15 @@ func handleGlobalFlags() {
 		util.Context.WorkingDir = os.ExpandEnv(wd)
 	}
 }
+
+// handleConfigFlags Applies global flags that override the configuration, after it is loaded
+func handleConfigFlags() {
+	if branch := GetStringFlag(rootCmd, "repo-branch", ""); branch != "" {
+		log.Debugf("Using deployment repository branch from flag: %s", branch)
+		util.Config.Repository.Branch = branch
+	}
+	if GetBoolFlag(rootCmd, "offline", false) {
+		log.Debugf("Running offline from flag")
+		util.Config.Repository.Offline = true
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+	"strings"
+)
+
+const (
+	setFlag   = "set"
+	unsetFlag = "unset"
+)
+
+var InvalidParameterErr = errors.New("invalid parameter, use KEY=VALUE")
+
+var (
+	updateEnvCmd = &cobra.Command{
+		Use:   "env NAME {--set KEY=VALUE | --unset KEY}...",
+		Short: "Updates the parameters of an environment class, nested parameters can be set using dotted keys like 'features.debug=true'",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName := GetArg(args, 0)
+			env := gitops.NewEnvClass(envName)
+			if err := env.Read(); err != nil {
+				log.Fatal(err, "Error loading env class %s", envName)
+			}
+			if err := setEnvParameters(cmd, env); err != nil {
+				log.Fatal(err, "Invalid parameters for env class %s", envName)
+			}
+			if keys, err := cmd.Flags().GetStringSlice(unsetFlag); err == nil {
+				for _, key := range keys {
+					if !env.UnsetParameter(key) {
+						log.Warnf("Parameter %s is not set on env class %s, ignoring", key, envName)
+					}
+				}
+			}
+			if err := env.Update(); err != nil {
+				log.Fatal(err, "Error updating env class %s", envName)
+			}
+			if _, err := PushChanges(cmd, git.CommitInfo{Summary: "update env class " + envName, Operation: git.UpdateOperation, Resource: "env/" + envName}); err != nil {
+				log.Fatal(err, "Error pushing changes to deployment repository")
+			}
+		},
+	}
+)
+
+func init() {
+	AddSetFlag(updateEnvCmd)
+	updateEnvCmd.Flags().StringSliceP(unsetFlag, "u", []string{}, "--unset|-u KEY (can be repeated)")
+	AddPushFlags(updateEnvCmd)
+	updateCmd.AddCommand(updateEnvCmd)
+}
+
+func AddSetFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP(setFlag, "S", []string{}, "--set|-S KEY=VALUE (can be repeated)")
+}
+
+// setEnvParameters Sets all --set KEY=VALUE flags on the env class, values are parsed as YAML scalars so booleans and numbers keep their type
+func setEnvParameters(cmd *cobra.Command, env *gitops.EnvClass) error {
+	if params, err := cmd.Flags().GetStringArray(setFlag); err == nil {
+		for _, param := range params {
+			parts := strings.SplitN(param, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return log.Errf(InvalidParameterErr, "Invalid parameter %s", param)
+			}
+			var value interface{}
+			if err = yaml.Unmarshal([]byte(parts[1]), &value); err != nil || value == nil {
+				value = parts[1]
+			}
+			env.SetParameter(strings.TrimSpace(parts[0]), value)
+		}
+	}
+	return nil
+}
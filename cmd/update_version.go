@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/spf13/cobra"
 	"gosh/git"
+	"gosh/gitops"
 	"gosh/log"
 )
 
+const allowDowngradeFlag = "allow-downgrade"
+
 var (
 	updateVersionCmd = &cobra.Command{
 		Use:  "version {--stage STAGE|--release RELEASE} APP VERSION",
@@ -13,27 +17,44 @@ var (
 		Run: func(cmd *cobra.Command, args []string) {
 			appName := GetArg(args, 0)
 			version := GetArg(args, 1)
-			flag, value, err := GetMutuallyExclusiveStringFlag(cmd, "stage", "release")
+			flag, value, err := GetMutuallyExclusiveStringFlag(cmd, StageFlag, ReleaseFlag)
 			if err == MutuallyExclusiveFlagsSetErr {
 				log.Fatal(err, "You must specify either --stage or --release, not both")
 			}
 			if err == RequiredFlagNotSetErr {
 				log.Fatal(err, "You must specify --stage or --release")
 			}
+			allowDowngrade := GetBoolFlag(cmd, allowDowngradeFlag, false)
+			force := GetBoolFlag(cmd, ForceFlag, false)
+			if force && flag == ReleaseFlag {
+				//releases have no pipeline order, a forced update would be recorded without anything being forced
+				log.Fatal(MutuallyExclusiveFlagsSetErr, "--force only applies to stages, it cannot be combined with --release")
+			}
 			if appList, err := LoadAppList(flag, value); err == nil {
-				if err = appList.UpdateVersion(appName, version); err == nil {
-					push := GetBoolFlag(cmd, "push", false)
-					if push {
-						if repo, err := git.NewDeploymentRepository("", false); err == nil {
-							err = repo.Push(GetStringFlag(cmd, "message", ""))
-							if err == nil {
-								log.Infof("Updated app %s to version %s for %s %s", appName, version, flag, value)
-							} else {
-								log.Fatal(err, "Error pushing updates to deployment repository")
-							}
-						} else {
-							log.Fatal(err, "Error opening working dir as Git repository")
+				err = gitops.CheckVersionUpdate(appList, appName, version, allowDowngrade)
+				if err != nil {
+					log.Fatal(err, "Refusing to update app %s to version %s for %s %s", appName, version, flag, value)
+				}
+				from := appList.GetVersions("", appName)[appName]
+				err = updateAppVersion(appList, appName, version, force)
+				if err == nil {
+					replay := func() (git.CommitInfo, error) {
+						appList, err := LoadAppList(flag, value)
+						if err != nil {
+							return git.CommitInfo{}, err
+						}
+						if err = gitops.CheckVersionUpdate(appList, appName, version, allowDowngrade); err != nil {
+							return git.CommitInfo{}, err
 						}
+						from := appList.GetVersions("", appName)[appName]
+						return updateVersionCommitInfo(flag, value, appName, from, version), updateAppVersion(appList, appName, version, force)
+					}
+					if pushed, err := PushVersionChanges(cmd, updateVersionCommitInfo(flag, value, appName, from, version), replay); err == nil {
+						if pushed {
+							log.Infof("Updated app %s to version %s for %s %s", appName, version, flag, value)
+						}
+					} else {
+						log.Fatal(err, "Error pushing updates to deployment repository")
 					}
 				} else {
 					log.Fatal(err, "Error updating app %s to version %s for %s %s", appName, version, flag, value)
@@ -45,10 +66,35 @@ var (
 	}
 )
 
+func updateAppVersion(appList gitops.AppList, appName string, version string, force bool) error {
+	if stage, ok := appList.(*gitops.Stage); ok && force {
+		return stage.ForceUpdateVersion(appName, version)
+	}
+	return appList.(gitops.UpdatableAppList).UpdateVersion(appName, version)
+}
+
+func updateVersionCommitInfo(appListType string, appListName string, appName string, from string, version string) git.CommitInfo {
+	change := git.CommitInfo{
+		Summary:     fmt.Sprintf("update %s to %s in %s %s", appName, version, appListType, appListName),
+		Operation:   git.UpdateVersionOperation,
+		App:         appName,
+		FromVersion: from,
+		Version:     version,
+	}
+	switch appListType {
+	case StageFlag:
+		change.Stage = appListName
+	case ReleaseFlag:
+		change.Release = appListName
+	}
+	return change
+}
+
 func init() {
 	AddReleaseFlag(updateVersionCmd)
 	AddStageFlag(updateVersionCmd)
-	updateVersionCmd.Flags().BoolP("push", "p", false, "--push|-p   Push changes to the remote repository (default: false)")
-	updateVersionCmd.Flags().StringP("message", "m", "", "--message|-m \"COMMIT MESSAGE\" (optional, only used when --push is specified)")
+	AddForceFlag(updateVersionCmd)
+	updateVersionCmd.Flags().Bool(allowDowngradeFlag, false, "--allow-downgrade   Allow updating to a version lower than the current version (default: false)")
+	AddPushFlags(updateVersionCmd)
 	updateCmd.AddCommand(updateVersionCmd)
 }
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/gitops"
+	"gosh/log"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	fileFlag   = "file"
+	formatFlag = "format"
+)
+
+var (
+	InvalidVersionUpdatesErr = errors.New("one or more versions cannot be updated")
+	updateVersionsCmd        = &cobra.Command{
+		Use:   "versions --stage STAGE [--file FILE] [--format yaml|properties|json] [FLAGS]...",
+		Short: "Updates the versions of multiple apps in a stage at once",
+		Long: `Updates the versions of multiple apps in a stage at once from a file, or stdin when no file or '-' is given.
+
+The file contains app names and versions, e.g. 'my-app: 1.2.3' in yaml, 'my-app=1.2.3' in properties or
+{"my-app": "1.2.3"} in json. The format is taken from the file extension, use --format to override it. Every app
+is checked before anything is changed, so either all versions are updated and committed together or none are`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Tracef("running command update versions with args: %v", args)
+			file := GetStringFlag(cmd, fileFlag, "-")
+			format := GetStringFlag(cmd, formatFlag, gitops.VersionsFormatFromFile(file))
+			var data []byte
+			var err error
+			if file == "-" {
+				data, err = ioutil.ReadAll(os.Stdin)
+			} else {
+				data, err = ioutil.ReadFile(file)
+			}
+			if err != nil {
+				log.Fatal(err, "Error reading versions from %s", file)
+			}
+			versions, err := gitops.ParseVersions(data, format)
+			if err != nil {
+				log.Fatal(err, "Error parsing versions from %s", file)
+			}
+			stage := gitops.NewStage(GetStringFlag(cmd, StageFlag, ""))
+			if err = stage.Read(); err != nil {
+				log.Fatal(err, "Error loading stage %s", stage.Name)
+			}
+			allowDowngrade := GetBoolFlag(cmd, allowDowngradeFlag, false)
+			force := GetBoolFlag(cmd, ForceFlag, false)
+			if invalid := checkVersionUpdates(stage, versions, allowDowngrade); len(invalid) > 0 {
+				log.Fatal(InvalidVersionUpdatesErr, "Refusing to update stage %s, %d of %d versions are invalid:\n  %s",
+					stage.Name, len(invalid), len(versions), strings.Join(invalid, "\n  "))
+			}
+			previous, err := stageVersions(stage.Name)
+			if err != nil {
+				log.Fatal(err, "Error loading stage %s", stage.Name)
+			}
+			changed, err := stage.UpdateVersions(versions, force)
+			if err != nil {
+				log.Fatal(err, "Error updating versions of stage %s", stage.Name)
+			}
+			if len(changed) == 0 {
+				log.Infof("All versions of stage %s are up to date", stage.Name)
+				return
+			}
+			replay := func() (git.CommitInfo, error) {
+				stage := gitops.NewStage(stage.Name)
+				if err := stage.Read(); err != nil {
+					return git.CommitInfo{}, err
+				}
+				if invalid := checkVersionUpdates(stage, versions, allowDowngrade); len(invalid) > 0 {
+					return git.CommitInfo{}, log.Errf(InvalidVersionUpdatesErr, "Versions of stage %s changed on the remote:\n  %s", stage.Name, strings.Join(invalid, "\n  "))
+				}
+				previous, err := stageVersions(stage.Name)
+				if err != nil {
+					return git.CommitInfo{}, err
+				}
+				changed, err := stage.UpdateVersions(versions, force)
+				return updateVersionsCommitInfo(stage.Name, previous, changed), err
+			}
+			if pushed, err := PushVersionChanges(cmd, updateVersionsCommitInfo(stage.Name, previous, changed), replay); err == nil {
+				if pushed {
+					log.Infof("Updated %d app versions for stage %s", len(changed), stage.Name)
+				}
+			} else {
+				log.Fatal(err, "Error pushing updates to deployment repository")
+			}
+		},
+	}
+)
+
+func updateVersionsCommitInfo(stageName string, previous map[string]string, changed map[string]string) git.CommitInfo {
+	return git.CommitInfo{
+		Summary:   fmt.Sprintf("update %d versions in stage %s", len(changed), stageName),
+		Operation: git.UpdateVersionOperation,
+		Stage:     stageName,
+		Changes:   versionChanges(previous, changed),
+	}
+}
+
+// checkVersionUpdates Checks all version updates and returns a description of every invalid one, sorted by app name
+func checkVersionUpdates(stage *gitops.Stage, versions map[string]string, allowDowngrade bool) []string {
+	apps := make([]string, 0, len(versions))
+	for appName := range versions {
+		apps = append(apps, appName)
+	}
+	sort.Strings(apps)
+	var invalid []string
+	for _, appName := range apps {
+		if err := gitops.CheckVersionUpdate(stage, appName, versions[appName], allowDowngrade); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s %s: %v", appName, versions[appName], err))
+		}
+	}
+	return invalid
+}
+
+func init() {
+	AddStageFlag(updateVersionsCmd)
+	_ = updateVersionsCmd.MarkFlagRequired(StageFlag)
+	updateVersionsCmd.Flags().StringP(fileFlag, "f", "-", "--file|-f FILE   File with app versions, '-' reads from stdin (default: -)")
+	updateVersionsCmd.Flags().String(formatFlag, "", "--format yaml|properties|json (default: based on the file extension, yaml for stdin)")
+	AddForceFlag(updateVersionsCmd)
+	updateVersionsCmd.Flags().Bool(allowDowngradeFlag, false, "--allow-downgrade   Allow updating to versions lower than the current versions (default: false)")
+	AddPushFlags(updateVersionsCmd)
+	updateCmd.AddCommand(updateVersionsCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/gitops"
+	"gosh/log"
+	"os"
+)
+
+var (
+	InventoryInvalidErr = errors.New("the inventory has validation issues")
+	validateCmd         = &cobra.Command{
+		Use:   "validate",
+		Short: "Validates the entire inventory and exits with a nonzero exit code when problems are found, e.g. for CI",
+		Long: `Validates the entire inventory and exits with a nonzero exit code when problems are found, e.g. for CI.
+
+Reported are: unparsable YAML files, group classes pointing to missing app files, apps missing from their group's
+class list, versions of nonexistent apps in stages and releases, stages out of sync with their stage release,
+target classes that do not exist, and app files or templates with unresolved gosh placeholders`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			issues, err := gitops.ValidateInventory()
+			if err != nil {
+				log.Fatal(err, "Could not validate inventory")
+			}
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if len(issues) > 0 {
+				log.Fatal(InventoryInvalidErr, "Found %d issue(s) in the inventory", len(issues))
+			}
+			_, _ = fmt.Fprintln(os.Stderr, "The inventory is valid")
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var (
+	verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verifies the deployment repository, e.g. the signatures of its commits",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
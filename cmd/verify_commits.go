@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gosh/git"
+	"gosh/log"
+	"gosh/util"
+	"os"
+	"text/tabwriter"
+)
+
+const allowedSignersFlag = "allowed-signers"
+
+var (
+	UnverifiedCommitsErr = errors.New("commits are not signed by an allowed signer")
+	verifyCommitsCmd     = &cobra.Command{
+		Use:   "commits [--from REVISION] [--to REVISION] [--allowed-signers FILE]",
+		Short: "Verifies that commits are signed by an allowed signer",
+		Long: `Verifies that the commits reachable from --to (default: HEAD) but not from --from are signed by an allowed signer,
+all commits are verified when --from is not set.
+
+The allowed signers file uses the git format for SSH keys, 'PRINCIPALS [OPTIONS] KEYTYPE KEY [COMMENT]', and may contain
+armored OpenPGP public key blocks. It defaults to the configured auth.signing.allowed_signers_file`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Tracef("running command verify commits with args: %v", args)
+			file := GetStringFlag(cmd, allowedSignersFlag, util.Config.Signing.AllowedSignersFile)
+			if file == "" {
+				log.Fatal(RequiredFlagNotSetErr, "You must specify --allowed-signers or configure auth.signing.allowed_signers_file")
+			}
+			signers, err := git.LoadAllowedSigners(file)
+			if err != nil {
+				log.Fatal(err, "Error loading allowed signers from %s", file)
+			}
+			repo, err := git.OpenLocalDeploymentRepository()
+			if err != nil {
+				log.Fatal(err, "Error opening working dir as Git repository")
+			}
+			verifications, err := repo.VerifyCommits(GetStringFlag(cmd, fromFlag, ""), GetStringFlag(cmd, toFlag, ""), signers)
+			if err != nil {
+				log.Fatal(err, "Could not verify commits")
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "COMMIT\tSTATUS\tSIGNER\tKEY\tSUMMARY")
+			unverified := 0
+			for _, v := range verifications {
+				status := "verified"
+				if v.Err != nil {
+					status = v.Err.Error()
+					unverified++
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", shortCommit(v.Commit.String()), status, orNone(v.Signer), orNone(v.Key), v.Summary)
+			}
+			_ = w.Flush()
+			if unverified > 0 {
+				log.Fatal(UnverifiedCommitsErr, "%d of %d commits are not signed by an allowed signer", unverified, len(verifications))
+			}
+		},
+	}
+)
+
+func init() {
+	verifyCommitsCmd.Flags().String(fromFlag, "", "--from REVISION   Only verify commits after this commit, tag or branch")
+	verifyCommitsCmd.Flags().String(toFlag, "", "--to REVISION   Verify commits up to this commit, tag or branch (default: HEAD)")
+	verifyCommitsCmd.Flags().String(allowedSignersFlag, "", "--allowed-signers FILE   Allowed signers file (default: auth.signing.allowed_signers_file)")
+	verifyCmd.AddCommand(verifyCommitsCmd)
+}
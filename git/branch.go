@@ -0,0 +1,157 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"gosh/log"
+	"gosh/util"
+)
+
+var (
+	WrongBranchErr        = errors.New("working dir is not on the configured deployment repository branch")
+	UncommittedChangesErr = errors.New("working dir has uncommitted changes")
+)
+
+// currentBranch Returns the reference of the branch checked out in the working dir
+func (repo *DeploymentRepository) currentBranch() (plumbing.ReferenceName, error) {
+	head, err := repo.git.Head()
+	if err != nil {
+		return "", log.Errf(err, "Could not resolve HEAD")
+	}
+	if !head.Name().IsBranch() {
+		return "", log.Errf(InvalidDeploymentRepoErr, "The working dir has no branch checked out")
+	}
+	return head.Name(), nil
+}
+
+// branchRefSpec Returns the refspec to pull or push only the given branch
+func branchRefSpec(branch plumbing.ReferenceName) config.RefSpec {
+	return config.RefSpec(fmt.Sprintf("%s:%s", branch, branch))
+}
+
+// checkBranch Returns WrongBranchErr when a branch is configured and another branch is checked out in the working dir
+func (repo *DeploymentRepository) checkBranch() error {
+	branch := util.Config.Repository.Branch
+	if branch == "" {
+		return nil
+	}
+	current, err := repo.currentBranch()
+	if err != nil {
+		return err
+	}
+	if current != plumbing.NewBranchReferenceName(branch) {
+		return log.Errf(WrongBranchErr, "The working dir is on branch %s instead of the configured branch %s, check it out with git checkout %s", current.Short(), branch, branch)
+	}
+	return nil
+}
+
+// checkoutBranch Checks out the configured branch, creating it from the remote branch when it does not exist in the
+// working dir yet. Nothing changes when no branch is configured, checking out fails when the working dir has changes
+func (repo *DeploymentRepository) checkoutBranch() error {
+	branch := util.Config.Repository.Branch
+	if branch == "" || repo.checkBranch() == nil {
+		return nil
+	}
+	ref := plumbing.NewBranchReferenceName(branch)
+	if _, err := repo.git.Reference(ref, false); err != nil {
+		hash, err := repo.fetchBranch(branch)
+		if err != nil {
+			return err
+		}
+		if err = repo.git.Storer.SetReference(plumbing.NewHashReference(ref, hash)); err != nil {
+			return log.Errf(err, "Could not create branch %s", branch)
+		}
+		if err = repo.git.CreateBranch(&config.Branch{Name: branch, Remote: "origin", Merge: ref}); err != nil && err != git.ErrBranchExists {
+			return log.Errf(err, "Could not track remote branch %s", branch)
+		}
+	}
+	w, err := repo.git.Worktree()
+	if err != nil {
+		return log.Errf(err, "Error accessing working tree in working dir")
+	}
+	//go-git switches HEAD before it detects changes that would be overwritten, check first
+	if repo.hasTrackedChanges(w) {
+		return log.Errf(UncommittedChangesErr, "Commit or discard the changes in the working dir before checking out branch %s", branch)
+	}
+	if repo.isSparse() {
+		err = repo.checkoutSparseBranch(ref)
+	} else {
+		err = w.Checkout(&git.CheckoutOptions{Branch: ref})
+	}
+	if err != nil {
+		return log.Errf(err, "Could not check out branch %s", branch)
+	}
+	log.Infof("Checked out deployment repository branch %s", branch)
+	return nil
+}
+
+// checkoutSparseBranch Points HEAD to the branch and checks out the sparse directories only, go-git would check out all files
+func (repo *DeploymentRepository) checkoutSparseBranch(ref plumbing.ReferenceName) error {
+	branch, err := repo.git.Reference(ref, true)
+	if err != nil {
+		return err
+	}
+	if err = repo.git.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, ref)); err != nil {
+		return err
+	}
+	return repo.checkoutSparse(branch.Hash())
+}
+
+// hasTrackedChanges Returns true when tracked files in the worktree are changed, untracked files are ignored
+func (repo *DeploymentRepository) hasTrackedChanges(w *git.Worktree) bool {
+	status, err := repo.status(w)
+	if err != nil {
+		return true
+	}
+	for _, file := range status {
+		if (file.Staging != git.Unmodified && file.Staging != git.Untracked) || (file.Worktree != git.Unmodified && file.Worktree != git.Untracked) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchBranch Fetches a branch from the remote and returns the commit it points to
+func (repo *DeploymentRepository) fetchBranch(branch string) (plumbing.Hash, error) {
+	if err := repo.checkOnline("fetch remote branch " + branch); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	//fetching into a private ref instead of the remote tracking branch, go-git fails to update packed refs of repositories cloned by git
+	fetchRef := plumbing.ReferenceName(fetchRefPrefix + branch)
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), fetchRef))
+	err := repo.git.Fetch(&git.FetchOptions{Auth: repo.auth, RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}, Depth: repo.fetchDepth()})
+	if err == transport.ErrEmptyUploadPackRequest {
+		//go-git fails instead of updating the ref when a shallow clone already has the commit of the remote branch
+		return repo.remoteBranchHash(branch)
+	} else if err != nil && err != git.NoErrAlreadyUpToDate {
+		return plumbing.ZeroHash, log.Errf(err, "Could not fetch remote branch %s", branch)
+	}
+	defer func() { _ = repo.git.Storer.RemoveReference(fetchRef) }()
+	ref, err := repo.git.Reference(fetchRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, log.Errf(err, "Could not resolve fetched remote branch %s", branch)
+	}
+	return ref.Hash(), nil
+}
+
+// remoteBranchHash Lists the branches of the remote and returns the commit the branch points to
+func (repo *DeploymentRepository) remoteBranchHash(branch string) (plumbing.Hash, error) {
+	remote, err := repo.git.Remote("origin")
+	if err != nil {
+		return plumbing.ZeroHash, log.Errf(err, "Could not find remote origin")
+	}
+	refs, err := remote.List(&git.ListOptions{Auth: repo.auth})
+	if err != nil {
+		return plumbing.ZeroHash, log.Errf(err, "Could not list remote branches")
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.NewBranchReferenceName(branch) {
+			return ref.Hash(), nil
+		}
+	}
+	return plumbing.ZeroHash, log.Errf(plumbing.ErrReferenceNotFound, "Remote branch %s does not exist", branch)
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"bytes"
+	"gosh/log"
+	"gosh/util"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	DefaultCommitMessageTemplate = `{{if .Summary}}chore(gosh): {{.Summary}}{{else}}` + DefaultCommitMessage + `{{end}}`
+	ForcedCommitNote             = "Forced: the stage pipeline order was not enforced for this change"
+	ReviewBranchPrefix           = "gosh/"
+	maxReviewBranchSlugLength    = 60
+)
+
+var (
+	branchSlugRegexp = regexp.MustCompile(`[^a-z0-9.]+`)
+	branchDotsRegexp = regexp.MustCompile(`\.{2,}`)
+)
+
+// CommitInfo Describes the change made by a gosh command, used to render the commit message and its gosh trailers
+type CommitInfo struct {
+	//Message is used instead of the commit message template when set, e.g. from the --message flag
+	Message string
+	//Summary a short description of the change, e.g. 'update my-app to 1.2.3 in stage dev'
+	Summary   string
+	Operation Operation
+	//Resource the resource that was created, deleted, moved or renamed, e.g. 'app/my-app'
+	Resource    string
+	App         string
+	Version     string
+	FromVersion string
+	Stage       string
+	Release     string
+	Target      string
+	//Changes the version changes of multiple apps, App, Version and FromVersion are used when empty
+	Changes  []AppVersionChange
+	Forced   bool
+	BuildUrl string
+}
+
+// CommitMessage Renders the commit message for the change, followed by its gosh trailers.
+//
+// The message is the Message of the change or the configured commit message template, the build URL is added from the
+// configuration
+func CommitMessage(info CommitInfo) (string, error) {
+	if info.BuildUrl == "" {
+		info.BuildUrl = util.Config.Commits.BuildUrl
+	}
+	msg := strings.TrimSpace(info.Message)
+	if msg == "" {
+		format := util.Config.Commits.MessageTemplate
+		if format == "" {
+			format = DefaultCommitMessageTemplate
+		}
+		t, err := template.New("commit").Parse(format)
+		if err != nil {
+			return "", log.Errf(err, "Invalid commit message template %s", format)
+		}
+		result := new(bytes.Buffer)
+		if err = t.Execute(result, info); err != nil {
+			return "", log.Errf(err, "Could not render commit message template %s", format)
+		}
+		msg = strings.TrimSpace(result.String())
+	}
+	if msg == "" {
+		msg = DefaultCommitMessage
+	}
+	if info.Forced {
+		msg += "\n\n" + ForcedCommitNote
+	}
+	if trailers := info.trailers(); len(trailers) > 0 {
+		msg += "\n\n" + formatTrailers(trailers)
+	}
+	return msg, nil
+}
+
+// ReviewBranchName Returns a branch name for reviewing the change, named after its summary with a timestamp to keep it
+// unique, e.g. 'gosh/update-my-app-to-1.2.3-in-stage-dev-20210924153000'
+func ReviewBranchName(info CommitInfo, now time.Time) string {
+	summary := info.Summary
+	if summary == "" && info.Operation != 0 {
+		summary = info.Operation.String()
+	}
+	slug := branchSlugRegexp.ReplaceAllString(strings.ToLower(summary), "-")
+	slug = branchDotsRegexp.ReplaceAllString(slug, ".")
+	if len(slug) > maxReviewBranchSlugLength {
+		slug = slug[:maxReviewBranchSlugLength]
+	}
+	slug = strings.Trim(slug, "-.")
+	if slug == "" {
+		slug = "changes"
+	}
+	return ReviewBranchPrefix + slug + "-" + now.Format("20060102150405")
+}
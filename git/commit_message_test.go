@@ -0,0 +1,83 @@
+package git
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CommitMessageSuite struct {
+	suite.Suite
+}
+
+func (suite *CommitMessageSuite) TearDownTest() {
+	util.Config.Commits = util.CommitsConfig{}
+}
+
+func (suite *CommitMessageSuite) TestDefaultCommitMessage() {
+	r := suite.Require()
+	msg, err := CommitMessage(CommitInfo{Summary: "update my-app to 1.2.3 in stage dev", App: "my-app"})
+	r.Nil(err)
+	r.Equal("chore(gosh): update my-app to 1.2.3 in stage dev", msg)
+
+	msg, err = CommitMessage(CommitInfo{})
+	r.Nil(err)
+	r.Equal(DefaultCommitMessage, msg)
+
+	util.Config.Commits.BuildUrl = "https://ci.example.com/builds/42"
+	msg, err = CommitMessage(CommitInfo{Summary: "create stage qa"})
+	r.Nil(err)
+	r.Equal("chore(gosh): create stage qa\n\nGosh-Build-Url: https://ci.example.com/builds/42", msg)
+}
+
+func (suite *CommitMessageSuite) TestCommitMessageTrailers() {
+	r := suite.Require()
+	msg, err := CommitMessage(CommitInfo{
+		Message:   "promote to prod",
+		Operation: PromoteOperation,
+		Stage:     "prod",
+		Changes:   []AppVersionChange{{App: "app1", From: "1.0.0", To: "1.1.0"}, {App: "app2", To: "2.0.0"}},
+		Forced:    true,
+	})
+	r.Nil(err)
+	r.Equal(`promote to prod
+
+`+ForcedCommitNote+`
+
+Gosh-Operation: promote
+Gosh-Target-List: stage/prod
+Gosh-App: app1
+Gosh-From-Version: 1.0.0
+Gosh-Version: 1.1.0
+Gosh-App: app2
+Gosh-Version: 2.0.0
+Gosh-Forced: true`, msg)
+}
+
+func (suite *CommitMessageSuite) TestCommitMessageTemplate() {
+	r := suite.Require()
+	util.Config.Commits.MessageTemplate = "deploy({{.Stage}}): {{.App}}@{{.Version}}"
+	msg, err := CommitMessage(CommitInfo{Summary: "update my-app", App: "my-app", Version: "1.2.3", Stage: "dev"})
+	r.Nil(err)
+	r.Equal("deploy(dev): my-app@1.2.3", msg)
+
+	util.Config.Commits.MessageTemplate = "{{.Unknown}}"
+	_, err = CommitMessage(CommitInfo{})
+	r.NotNil(err)
+}
+
+func (suite *CommitMessageSuite) TestReviewBranchName() {
+	r := suite.Require()
+	now := time.Date(2021, 9, 24, 15, 30, 0, 0, time.UTC)
+	r.Equal("gosh/update-my-app-to-1.2.3-in-stage-dev-20210924153000",
+		ReviewBranchName(CommitInfo{Summary: "update my-app to 1.2.3 in stage dev"}, now))
+	r.Equal("gosh/promote-20210924153000", ReviewBranchName(CommitInfo{Operation: PromoteOperation}, now))
+	r.Equal("gosh/changes-20210924153000", ReviewBranchName(CommitInfo{Summary: "..."}, now))
+	r.Equal("gosh/"+strings.Repeat("a", 60)+"-20210924153000", ReviewBranchName(CommitInfo{Summary: strings.Repeat("a", 80)}, now))
+}
+
+func TestCommitMessageSuite(t *testing.T) {
+	suite.Run(t, new(CommitMessageSuite))
+}
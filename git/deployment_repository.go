@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"github.com/artdarek/go-unzip"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	http_transport "github.com/go-git/go-git/v5/plumbing/transport/http"
@@ -14,6 +16,7 @@ import (
 	"gosh/log"
 	"gosh/util"
 	"io"
+	"math/rand"
 	"net/http"
 
 	"os"
@@ -26,25 +29,37 @@ type Repository interface {
 	Initialize() error
 	Clone() error
 	Pull() error
-	Push() error
-	CommitChanges(msg string) error
+	Push(msg string) error
+	Commit(msg string) error
 }
 
 const (
 	defaultDeploymentRepoTemplateUrl = "https://github.com/ndriessen/gosh-git-template/archive/refs/heads/master.zip"
 	defaultUnzipDirectory            = "gosh-git-template-master"
+	DefaultCommitMessage             = "chore: gosh version changes"
+	maxPushAttempts                  = 5
+	fetchRefPrefix                   = "refs/gosh/fetch/"
 )
 
 var (
 	WorkingDirNotEmptyErr    = errors.New("working dir is not empty")
 	WorkingDirEmptyErr       = errors.New("your working directory is empty, please initialize it first using gosh init")
 	InvalidDeploymentRepoErr = errors.New("working dir does not point to configured deployment repo or has an invalid structure")
+	PushRejectedErr          = errors.New("push rejected, the remote branch contains changes that are not in the working dir")
+	BranchExistsErr          = errors.New("branch already exists")
+	NothingToPushErr         = errors.New("no changes to push")
+	OfflineErr               = errors.New("running offline, the remote of the deployment repository cannot be accessed")
+	pushRetryBaseDelay       = 500 * time.Millisecond
 )
 
 type DeploymentRepository struct {
 	url  string
 	auth transport.AuthMethod
 	git  *git.Repository
+	//offline the remote is never accessed, e.g. to run without network
+	offline bool
+	//tags created with CreateTag, they are pushed together with the next push
+	tags []plumbing.ReferenceName
 }
 
 func isValid(repo *DeploymentRepository) bool {
@@ -59,7 +74,15 @@ func (repo *DeploymentRepository) OpenWorkingDir() error {
 		return WorkingDirEmptyErr
 	}
 	if gitRepo, err := git.PlainOpen(util.Context.WorkingDir); err == nil {
-		repo.git = gitRepo
+		if err = repo.open(gitRepo); err != nil {
+			return err
+		}
+		//no url means: use the deployment repo the working dir was cloned from
+		if repo.url == "" {
+			if remote, err := gitRepo.Remote("origin"); err == nil && len(remote.Config().URLs) > 0 {
+				repo.url = remote.Config().URLs[0]
+			}
+		}
 		return nil
 	} else {
 		return log.Errf(err, "Error opening working dir as git repository")
@@ -80,13 +103,8 @@ func (repo *DeploymentRepository) InitFromTemplate() error {
 }
 
 func NewDeploymentRepository(url string, cloneIfEmpty bool) (*DeploymentRepository, error) {
-	var repo *DeploymentRepository
-	if authMethod, err := initAuth(util.Config); err == nil {
-		repo = &DeploymentRepository{
-			url:  url,
-			auth: authMethod,
-		}
-	} else {
+	repo, err := newDeploymentRepository(url)
+	if err != nil {
 		return nil, err
 	}
 	if isDirectoryEmpty(util.Context.WorkingDir) {
@@ -103,6 +121,10 @@ func NewDeploymentRepository(url string, cloneIfEmpty bool) (*DeploymentReposito
 		if err := repo.OpenWorkingDir(); err != nil {
 			return nil, err
 		}
+		if repo.offline {
+			log.Debugf("Offline, using working directory %s without pulling", util.Context.WorkingDir)
+			return repo, nil
+		}
 		//is a valid deployment repo, pull changes
 		if err := repo.Pull(); err != nil && err != git.NoErrAlreadyUpToDate {
 			return nil, err
@@ -111,6 +133,44 @@ func NewDeploymentRepository(url string, cloneIfEmpty bool) (*DeploymentReposito
 	return repo, nil
 }
 
+// OpenLocalDeploymentRepository Opens the working dir as deployment repository to read it, e.g. its history. It needs
+// neither auth config nor the remote, operations that access the remote return OfflineErr
+func OpenLocalDeploymentRepository() (*DeploymentRepository, error) {
+	repo := &DeploymentRepository{offline: true}
+	if err := repo.OpenWorkingDir(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// OpenDeploymentRepository Opens the working dir as deployment repository without pulling remote changes, use it to
+// push changes made in the working dir
+func OpenDeploymentRepository() (*DeploymentRepository, error) {
+	repo, err := newDeploymentRepository("")
+	if err != nil {
+		return nil, err
+	}
+	if err = repo.OpenWorkingDir(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func newDeploymentRepository(url string) (*DeploymentRepository, error) {
+	if util.Config.Repository.Offline {
+		//the remote is never accessed, no auth needed
+		return &DeploymentRepository{url: url, offline: true}, nil
+	}
+	if authMethod, err := initAuth(util.Config); err == nil {
+		return &DeploymentRepository{
+			url:  url,
+			auth: authMethod,
+		}, nil
+	} else {
+		return nil, err
+	}
+}
+
 func initAuth(config *util.GoshConfig) (transport.AuthMethod, error) {
 	if config.Auth == nil {
 		return nil, errors.New("no auth configuration provided for GIT")
@@ -153,8 +213,20 @@ func decodeSecret(encodedPass string) string {
 
 func (repo *DeploymentRepository) openWorkingDir() error {
 	gitRepo, err := git.PlainOpen(util.Context.WorkingDir)
+	if err != nil {
+		return err
+	}
+	return repo.open(gitRepo)
+}
+
+// open Uses the git repository of the working dir, shallow clones are read through shallowStorage
+func (repo *DeploymentRepository) open(gitRepo *git.Repository) error {
+	gitRepo, err := withShallowStorage(gitRepo)
+	if err != nil {
+		return log.Errf(err, "Error opening shallow clone in working dir")
+	}
 	repo.git = gitRepo
-	return err
+	return nil
 }
 
 func (repo *DeploymentRepository) Initialize() error {
@@ -196,18 +268,32 @@ func isDirectoryEmpty(path string) bool {
 }
 
 func (repo *DeploymentRepository) Clone() error {
+	if err := repo.checkOnline("clone the deployment repository"); err != nil {
+		return err
+	}
 	if !isDirectoryEmpty(util.Context.WorkingDir) {
 		return WorkingDirNotEmptyErr
 	}
 	log.Infof("Cloning deployment repo %s into %s", repo.url, util.Context.WorkingDir)
 
-	if gitRepo, err := git.PlainClone(util.Context.WorkingDir, false, &git.CloneOptions{
+	options := &git.CloneOptions{
 		URL:               repo.url,
 		Auth:              repo.auth,
 		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
-		//Depth:             1, //causes Pull to fail...
-	}); err == nil {
-		repo.git = gitRepo
+		Depth:             util.Config.Repository.Depth,
+		//a sparse clone checks out the sparse directories itself
+		NoCheckout: util.Config.Repository.Sparse,
+	}
+	if branch := util.Config.Repository.Branch; branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+	if gitRepo, err := git.PlainClone(util.Context.WorkingDir, false, options); err == nil {
+		if err = repo.open(gitRepo); err != nil {
+			return err
+		}
+		if util.Config.Repository.Sparse {
+			return repo.sparseClone()
+		}
 		return nil
 	} else {
 		return log.Errf(err, "Error cloning deployment repository in %s", util.Context.WorkingDir)
@@ -221,8 +307,23 @@ func (repo *DeploymentRepository) Pull() error {
 	if !repo.isValidRepository() {
 		return InvalidDeploymentRepoErr
 	}
+	if err := repo.checkOnline("pull remote changes"); err != nil {
+		return err
+	}
+	if err := repo.checkoutBranch(); err != nil {
+		return err
+	}
+	branch, err := repo.currentBranch()
+	if err != nil {
+		return err
+	}
+	if repo.isShallow() || repo.isSparse() {
+		//go-git only pulls when the fetched commits reach HEAD, which a shallow fetch may not, and checks out all files
+		return repo.fastForward(branch)
+	}
 	if worktree, err := repo.git.Worktree(); err == nil {
-		if err = worktree.Pull(&git.PullOptions{Auth: repo.auth, RemoteName: "origin"}); err != nil && err != git.NoErrAlreadyUpToDate {
+		//go-git pulls the default branch of the remote into the current branch when no reference is given
+		if err = worktree.Pull(&git.PullOptions{Auth: repo.auth, RemoteName: "origin", ReferenceName: branch}); err != nil && err != git.NoErrAlreadyUpToDate {
 			return log.Errf(err, "Error updating working dir with remote")
 		}
 	} else {
@@ -231,39 +332,574 @@ func (repo *DeploymentRepository) Pull() error {
 	return nil
 }
 
+// fastForward Fetches the remote branch and fast-forwards the branch and working dir to it. The history of a shallow clone
+// can end before HEAD, the branch is then moved to the remote branch when it has no local commits
+func (repo *DeploymentRepository) fastForward(branch plumbing.ReferenceName) error {
+	hash, err := repo.fetchBranch(branch.Short())
+	if err != nil {
+		return err
+	}
+	head, err := repo.git.Head()
+	if err != nil {
+		return log.Errf(err, "Could not resolve HEAD")
+	}
+	if head.Hash() == hash || repo.isAncestor(hash, head.Hash()) {
+		log.Debugf("Branch %s is up to date with the remote branch", branch.Short())
+		return nil
+	}
+	if !repo.isAncestor(head.Hash(), hash) && repo.hasUnpushedCommits() {
+		return log.Errf(git.ErrNonFastForwardUpdate, "Branch %s has local commits and cannot be fast-forwarded to the remote branch", branch.Short())
+	}
+	w, err := repo.git.Worktree()
+	if err != nil {
+		return log.Errf(err, "Error accessing working tree in working dir")
+	}
+	if repo.hasTrackedChanges(w) {
+		return log.Errf(UncommittedChangesErr, "Commit or discard the changes in the working dir before pulling branch %s", branch.Short())
+	}
+	if err = repo.resetTo(hash); err != nil {
+		return log.Errf(err, "Error updating working dir with remote")
+	}
+	if err = repo.git.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", branch.Short()), hash)); err != nil {
+		return log.Errf(err, "Could not update remote tracking branch of %s", branch.Short())
+	}
+	return nil
+}
+
+// isAncestor Returns true when the first commit is in the history of the second one
+func (repo *DeploymentRepository) isAncestor(ancestor plumbing.Hash, hash plumbing.Hash) bool {
+	ancestorCommit, err := repo.git.CommitObject(ancestor)
+	if err != nil {
+		return false
+	}
+	commit, err := repo.git.CommitObject(hash)
+	if err != nil {
+		return false
+	}
+	found, err := ancestorCommit.IsAncestor(commit)
+	return err == nil && found
+}
+
 func (repo *DeploymentRepository) Push(msg string) error {
-	if w, err := repo.git.Worktree(); err == nil {
-		//err = w.AddGlob(filepath.Join("inventory", "classes", "*"))
-		err = w.AddWithOptions(&git.AddOptions{
-			All: true,
-		})
+	return repo.PushWithReplay(msg, nil)
+}
+
+// PushWithReplay Commits all changes in the working dir and pushes them, retrying with backoff when the push is rejected
+// because the remote branch contains commits that are not in the working dir.
+//
+// Before each retry the working dir is reset to the remote branch and replay is called to apply the changes again on
+// top of the new remote state, instead of merging the commits. Replay returns the commit message for the replayed
+// changes, as they can differ from the original ones. Without replay, a rejected push returns PushRejectedErr.
+func (repo *DeploymentRepository) PushWithReplay(msg string, replay func() (string, error)) error {
+	if err := repo.checkOnline("push changes, use --commit to commit them in the working dir"); err != nil {
+		return err
+	}
+	if msg == "" {
+		msg = DefaultCommitMessage
+	}
+	if replay != nil && repo.hasUnpushedCommits() {
+		log.Warnf("The working dir contains commits that are not pushed yet, a rejected push cannot be replayed")
+		replay = nil
+	}
+	for attempt := 1; ; attempt++ {
+		if err := repo.commitAll(msg); err != nil {
+			return err
+		}
+		branch, err := repo.currentBranch()
 		if err != nil {
 			return err
 		}
-		if msg == "" {
-			msg = "chore: gosh version changes"
+		refSpecs := append([]config.RefSpec{branchRefSpec(branch)}, repo.tagRefSpecs(branch)...)
+		err = repo.git.Push(&git.PushOptions{Auth: repo.auth, RemoteName: "origin", RefSpecs: refSpecs})
+		if err == nil || err == git.NoErrAlreadyUpToDate {
+			return nil
+		}
+		if !isPushRejected(err) {
+			return err
+		}
+		if replay == nil {
+			return log.Errf(PushRejectedErr, "Push was rejected, pull the remote changes and try again: %v", err)
+		}
+		if attempt >= maxPushAttempts {
+			return log.Errf(PushRejectedErr, "Push was still rejected after %d attempts: %v", attempt, err)
+		}
+		delay := pushRetryDelay(attempt)
+		log.Warnf("Push was rejected, replaying changes on the remote state and retrying in %s (attempt %d of %d)", delay, attempt+1, maxPushAttempts)
+		time.Sleep(delay)
+		if err = repo.resetToRemote(); err != nil {
+			return err
+		}
+		if msg, err = replay(); err != nil {
+			return log.Errf(err, "Could not replay changes on the remote state")
+		}
+	}
+}
+
+// PushBranch Commits all changes in the working dir on a new branch and pushes only that branch, e.g. to have the
+// changes reviewed in a merge request. The current branch is left untouched and checked out again afterwards, the new
+// branch is removed locally once it is pushed
+func (repo *DeploymentRepository) PushBranch(msg string, branch string) error {
+	if err := repo.checkOnline("push branch " + branch); err != nil {
+		return err
+	}
+	if msg == "" {
+		msg = DefaultCommitMessage
+	}
+	head, err := repo.git.Head()
+	if err != nil {
+		return log.Errf(err, "Could not resolve HEAD")
+	}
+	if !head.Name().IsBranch() {
+		return log.Errf(InvalidDeploymentRepoErr, "The working dir has no branch checked out")
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	if _, err = repo.git.Reference(branchRef, false); err == nil {
+		return log.Errf(BranchExistsErr, "Branch %s already exists in the working dir", branch)
+	}
+	if err = repo.commitAll(msg); err != nil {
+		return err
+	}
+	commit, err := repo.git.Head()
+	if err != nil {
+		return log.Errf(err, "Could not resolve HEAD")
+	}
+	if commit.Hash() == head.Hash() && !repo.hasUnpushedCommits() {
+		return log.Errf(NothingToPushErr, "There are no changes to push to branch %s", branch)
+	}
+	if err = repo.git.Storer.SetReference(plumbing.NewHashReference(branchRef, commit.Hash())); err != nil {
+		return log.Errf(err, "Could not create branch %s", branch)
+	}
+	//the changes are kept on the new branch, reset the current branch and working dir to where they were
+	if err = repo.git.Storer.SetReference(head); err != nil {
+		return log.Errf(err, "Could not restore branch %s, the changes are committed on branch %s", head.Name().Short(), branch)
+	}
+	if err = repo.resetTo(head.Hash()); err != nil {
+		return log.Errf(err, "Could not reset working dir to branch %s, the changes are committed on branch %s", head.Name().Short(), branch)
+	}
+	if err = repo.git.Push(&git.PushOptions{Auth: repo.auth, RemoteName: "origin", RefSpecs: []config.RefSpec{branchRefSpec(branchRef)}}); err != nil {
+		if isPushRejected(err) {
+			return log.Errf(PushRejectedErr, "Push of branch %s was rejected, it already exists on the remote: %v", branch, err)
 		}
-		commit, err := w.Commit(msg, &git.CommitOptions{Committer: &object.Signature{
-			Name:  "gosh",
-			Email: "gosh@github.com",
-			When:  time.Now(),
-		}})
+		return log.Errf(err, "Could not push branch %s, the changes are committed on that branch in the working dir", branch)
+	}
+	return repo.git.Storer.RemoveReference(branchRef)
+}
+
+func (repo *DeploymentRepository) commitAll(msg string) error {
+	if err := repo.checkBranch(); err != nil {
+		return err
+	}
+	w, err := repo.git.Worktree()
+	if err != nil {
+		return log.Errf(err, "error committing changes")
+	}
+	//err = w.AddGlob(filepath.Join("inventory", "classes", "*"))
+	if err = repo.stageAll(w); err != nil {
+		return err
+	}
+	if status, err := repo.status(w); err == nil && status.IsClean() {
+		log.Debugf("No changes to commit in working dir")
+		return nil
+	}
+	//load the signing key first, a commit that cannot be signed would be rejected by the remote
+	signer, err := newCommitSigner(util.Config.Signing)
+	if err != nil {
+		return err
+	}
+	committer := newCommitter()
+	commit, err := w.Commit(msg, &git.CommitOptions{Author: repo.newAuthor(committer), Committer: committer})
+	if err != nil {
+		return err
+	}
+	commitObject, _ := repo.git.CommitObject(commit)
+	log.Debugf("commit: %+v", commitObject)
+	return repo.signHead(signer)
+}
+
+// hasUnpushedCommits Returns true when HEAD differs from the remote tracking branch, e.g. after committing changes with --commit
+func (repo *DeploymentRepository) hasUnpushedCommits() bool {
+	head, err := repo.git.Head()
+	if err != nil {
+		return true
+	}
+	remote, err := repo.git.Reference(plumbing.NewRemoteReferenceName("origin", head.Name().Short()), true)
+	return err != nil || remote.Hash() != head.Hash()
+}
+
+// checkOnline Returns OfflineErr when the repository is offline, the action describes what needs the remote
+func (repo *DeploymentRepository) checkOnline(action string) error {
+	if repo.offline {
+		return log.Errf(OfflineErr, "Cannot %s while offline", action)
+	}
+	return nil
+}
+
+// isPushRejected Returns true if the push failed because the remote branch cannot be fast-forwarded to the local one.
+// Other rejections, e.g. by a protected branch or a pre-receive hook, cannot be solved by replaying the changes
+func isPushRejected(err error) bool {
+	if err == git.ErrForceNeeded || err == git.ErrNonFastForwardUpdate {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "non-fast-forward") || strings.Contains(msg, "fetch first")
+}
+
+// pushRetryDelay Doubles the delay for every attempt, with random jitter so concurrent pipelines do not retry in lockstep
+func pushRetryDelay(attempt int) time.Duration {
+	delay := pushRetryBaseDelay << (attempt - 1)
+	return delay + time.Duration(rand.Int63n(int64(delay)))
+}
+
+// resetToRemote Fetches the remote branch and resets the current branch and working dir to it, discarding local commits
+func (repo *DeploymentRepository) resetToRemote() error {
+	branch, err := repo.currentBranch()
+	if err != nil {
+		return err
+	}
+	hash, err := repo.fetchBranch(branch.Short())
+	if err != nil {
+		return err
+	}
+	if err = repo.resetTo(hash); err != nil {
+		return log.Errf(err, "Could not reset working dir to remote branch %s", branch.Short())
+	}
+	return nil
+}
+
+// resetTo Resets the current branch, index and working dir to the commit, discarding all changes
+func (repo *DeploymentRepository) resetTo(hash plumbing.Hash) error {
+	if repo.isSparse() {
+		head, err := repo.git.Head()
 		if err != nil {
 			return err
 		}
-		commitObject, _ := repo.git.CommitObject(commit)
-		log.Debugf("commit: %+v", commitObject)
+		if err = repo.git.Storer.SetReference(plumbing.NewHashReference(head.Name(), hash)); err != nil {
+			return err
+		}
+		return repo.checkoutSparse(hash)
+	}
+	w, err := repo.git.Worktree()
+	if err != nil {
+		return err
+	}
+	return w.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset})
+}
+
+// stageAll Stages all changes in the worktree, including deleted files which AddOptions.All does not stage
+func (repo *DeploymentRepository) stageAll(w *git.Worktree) error {
+	sparse := repo.isSparse()
+	//in a sparse clone only the changes in the sparse directories are staged, see status
+	if !sparse {
+		if err := w.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+			return err
+		}
+	}
+	status, err := repo.status(w)
+	if err != nil {
+		return err
+	}
+	for path, s := range status {
+		if s.Worktree == git.Deleted {
+			if _, err = w.Remove(path); err != nil {
+				return err
+			}
+		} else if sparse && s.Worktree != git.Unmodified {
+			if _, err = w.Add(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-		err = repo.git.Push(&git.PushOptions{Auth: repo.auth})
+// Tag Creates an annotated tag on the current HEAD commit and pushes it to the remote
+func (repo *DeploymentRepository) Tag(name string, msg string) error {
+	if !isValid(repo) || repo.git == nil {
+		return errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	if err := repo.checkOnline("push tag " + name); err != nil {
 		return err
+	}
+	head, err := repo.git.Head()
+	if err != nil {
+		return log.Errf(err, "Could not resolve HEAD to create tag %s", name)
+	}
+	if err = repo.CreateTag(name, msg, head.Hash()); err != nil {
+		return err
+	}
+	if err = repo.git.Push(&git.PushOptions{Auth: repo.auth, RefSpecs: []config.RefSpec{tagRefSpec(plumbing.NewTagReferenceName(name))}}); err != nil {
+		return log.Errf(err, "Could not push tag %s", name)
+	}
+	return nil
+}
+
+// CreateTag Creates an annotated tag on a commit in the working dir without pushing it, the tag is pushed together with
+// the next push, e.g. with the commit it tags
+func (repo *DeploymentRepository) CreateTag(name string, msg string, commit plumbing.Hash) error {
+	if !isValid(repo) || repo.git == nil {
+		return errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	ref, err := repo.git.CreateTag(name, commit, &git.CreateTagOptions{
+		Tagger:  newCommitter(),
+		Message: msg,
+	})
+	if err != nil {
+		return log.Errf(err, "Could not create tag %s", name)
+	}
+	repo.tags = append(repo.tags, ref.Name())
+	log.Infof("Created tag %s on commit %s", name, commit)
+	return nil
+}
+
+// tagRefSpecs Returns the refspecs of the tags to push with the branch: the tags created with CreateTag and the tags on
+// the commits that are not on the remote branch yet, e.g. created by an earlier command with --commit. Only the first
+// parents of HEAD are followed to find those commits
+func (repo *DeploymentRepository) tagRefSpecs(branch plumbing.ReferenceName) []config.RefSpec {
+	refSpecs := make([]config.RefSpec, 0, len(repo.tags))
+	pushed := map[plumbing.ReferenceName]bool{}
+	for _, name := range repo.tags {
+		refSpecs = append(refSpecs, tagRefSpec(name))
+		pushed[name] = true
+	}
+	tags := map[plumbing.Hash][]plumbing.ReferenceName{}
+	if iter, err := repo.git.Tags(); err == nil {
+		_ = iter.ForEach(func(ref *plumbing.Reference) error {
+			hash := ref.Hash()
+			//annotated tags point to a tag object, lightweight tags directly to the commit
+			if tag, err := repo.git.TagObject(hash); err == nil {
+				hash = tag.Target
+			}
+			tags[hash] = append(tags[hash], ref.Name())
+			return nil
+		})
+	}
+	head, err := repo.git.Head()
+	if len(tags) == 0 || err != nil {
+		return refSpecs
+	}
+	remote, err := repo.git.Reference(plumbing.NewRemoteReferenceName("origin", branch.Short()), true)
+	if err != nil {
+		return refSpecs
+	}
+	for hash := head.Hash(); hash != remote.Hash(); {
+		for _, name := range tags[hash] {
+			if !pushed[name] {
+				refSpecs = append(refSpecs, tagRefSpec(name))
+				pushed[name] = true
+			}
+		}
+		commit, err := repo.git.CommitObject(hash)
+		if err != nil || commit.NumParents() == 0 {
+			break
+		}
+		hash = commit.ParentHashes[0]
+	}
+	return refSpecs
+}
+
+func tagRefSpec(name plumbing.ReferenceName) config.RefSpec {
+	return config.RefSpec(name.String() + ":" + name.String())
+}
+
+// HasTag Returns true when the tag exists in the working dir, without fetching it from the remote
+func (repo *DeploymentRepository) HasTag(name string) bool {
+	if !isValid(repo) || repo.git == nil {
+		return false
+	}
+	_, err := repo.git.Tag(name)
+	return err == nil
+}
+
+// ResolveTag Returns the commit a tag points to, tags that are not known locally are fetched from the remote first
+func (repo *DeploymentRepository) ResolveTag(name string) (plumbing.Hash, error) {
+	if !isValid(repo) || repo.git == nil {
+		return plumbing.ZeroHash, errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	ref, err := repo.git.Tag(name)
+	if err == git.ErrTagNotFound && repo.offline {
+		return plumbing.ZeroHash, log.Errf(err, "Tag %s was not found in the working dir and cannot be fetched offline", name)
+	}
+	if err == git.ErrTagNotFound {
+		log.Debugf("Tag %s not found locally, fetching tags from remote", name)
+		if err = repo.git.Fetch(&git.FetchOptions{
+			Auth:     repo.auth,
+			RefSpecs: []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+			Depth:    repo.fetchDepth(),
+		}); err != nil && err != git.NoErrAlreadyUpToDate {
+			return plumbing.ZeroHash, log.Errf(err, "Could not fetch tags from remote")
+		}
+		ref, err = repo.git.Tag(name)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, log.Errf(err, "Could not find tag %s", name)
+	}
+	//annotated tags point to a tag object, lightweight tags directly to the commit
+	if tag, err := repo.git.TagObject(ref.Hash()); err == nil {
+		if commit, err := tag.Commit(); err == nil {
+			return commit.Hash, nil
+		} else {
+			return plumbing.ZeroHash, log.Errf(err, "Tag %s does not point to a commit", name)
+		}
+	}
+	return ref.Hash(), nil
+}
+
+// CheckoutTag Checks out the commit of a tag into a separate directory, leaving the working dir and its branch untouched
+func (repo *DeploymentRepository) CheckoutTag(name string, dir string) error {
+	hash, err := repo.ResolveTag(name)
+	if err != nil {
+		return err
+	}
+	if !isDirectoryEmpty(dir) {
+		return log.Errf(WorkingDirNotEmptyErr, "Cannot checkout tag %s, directory %s is not empty", name, dir)
+	}
+	log.Infof("Checking out tag %s (%s) into %s", name, hash, dir)
+	checkout, err := git.PlainClone(dir, false, &git.CloneOptions{
+		URL:  util.Context.WorkingDir,
+		Tags: git.AllTags,
+	})
+	if err != nil {
+		return log.Errf(err, "Could not create checkout directory %s", dir)
+	}
+	if w, err := checkout.Worktree(); err == nil {
+		if err = w.Checkout(&git.CheckoutOptions{Hash: hash}); err != nil {
+			return log.Errf(err, "Could not checkout tag %s in %s", name, dir)
+		}
 	} else {
-		return log.Errf(err, "error pushing changes")
+		return log.Errf(err, "Error accessing working tree in %s", dir)
+	}
+	return nil
+}
+
+// FileRevision A version of a file in the history of the deployment repository, Contents is nil if the file was deleted
+type FileRevision struct {
+	Hash     plumbing.Hash
+	Author   object.Signature
+	Message  string
+	Contents []byte
+}
+
+// FileHistory Returns the revisions of a file from the commits touching it, newest first.
+//
+// The path can be absolute or relative to the working dir.
+func (repo *DeploymentRepository) FileHistory(path string) ([]FileRevision, error) {
+	if !isValid(repo) || repo.git == nil {
+		return nil, errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	path, err := repo.relativePath(path)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := repo.git.Log(&git.LogOptions{FileName: &path, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, log.Errf(err, "Could not read history of %s", path)
 	}
+	revisions := make([]FileRevision, 0)
+	err = commits.ForEach(func(commit *object.Commit) error {
+		revision, err := fileRevision(commit, path)
+		if err != nil {
+			return err
+		}
+		revisions = append(revisions, revision)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found %d revisions of %s", len(revisions), path)
+	return revisions, nil
 }
 
+// FileAt Returns the revision of a file in a commit, the commit can be a (short) hash, tag or branch name
+func (repo *DeploymentRepository) FileAt(path string, revision string) (FileRevision, error) {
+	if !isValid(repo) || repo.git == nil {
+		return FileRevision{}, errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	path, err := repo.relativePath(path)
+	if err != nil {
+		return FileRevision{}, err
+	}
+	hash, err := repo.git.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return FileRevision{}, log.Errf(err, "Could not resolve revision %s", revision)
+	}
+	commit, err := repo.git.CommitObject(*hash)
+	if err != nil {
+		return FileRevision{}, log.Errf(err, "Revision %s is not a commit", revision)
+	}
+	return fileRevision(commit, path)
+}
+
+func fileRevision(commit *object.Commit, path string) (FileRevision, error) {
+	revision := FileRevision{Hash: commit.Hash, Author: commit.Author, Message: commit.Message}
+	if file, err := commit.File(path); err == nil {
+		contents, err := file.Contents()
+		if err != nil {
+			return revision, log.Errf(err, "Could not read %s in commit %s", path, commit.Hash)
+		}
+		revision.Contents = []byte(contents)
+	} else if err != object.ErrFileNotFound {
+		return revision, log.Errf(err, "Could not read %s in commit %s", path, commit.Hash)
+	}
+	return revision, nil
+}
+
+// relativePath Converts a path to the slash separated path relative to the working dir that git uses
+func (repo *DeploymentRepository) relativePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(util.Context.WorkingDir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", log.Errf(errors.New("path is not in the working dir"), "Path %s is not in working dir %s", path, util.Context.WorkingDir)
+		}
+		path = rel
+	}
+	return filepath.ToSlash(path), nil
+}
+
+func newSignature(cfg util.SignatureConfig) *object.Signature {
+	if cfg.Name == "" || cfg.Email == "" {
+		return nil
+	}
+	return &object.Signature{
+		Name:  cfg.Name,
+		Email: cfg.Email,
+		When:  time.Now(),
+	}
+}
+
+// newCommitter Returns the configured committer, gosh <gosh@github.com> by default
+func newCommitter() *object.Signature {
+	if committer := newSignature(util.Config.Commits.Committer); committer != nil {
+		return committer
+	}
+	return newSignature(util.SignatureConfig{Name: util.DefaultCommitterName, Email: util.DefaultCommitterEmail})
+}
+
+// newAuthor Returns the configured author, or the author or user from the git config, or the committer when none is set
+func (repo *DeploymentRepository) newAuthor(committer *object.Signature) *object.Signature {
+	if author := newSignature(util.Config.Commits.Author); author != nil {
+		return author
+	}
+	if cfg, err := repo.git.ConfigScoped(config.SystemScope); err == nil {
+		if author := newSignature(util.SignatureConfig{Name: cfg.Author.Name, Email: cfg.Author.Email}); author != nil {
+			return author
+		}
+		if author := newSignature(util.SignatureConfig{Name: cfg.User.Name, Email: cfg.User.Email}); author != nil {
+			return author
+		}
+	}
+	return committer
+}
+
+// Commit Commits all changes in the working dir without pushing them, nothing is committed when there are no changes
 func (repo *DeploymentRepository) Commit(msg string) error {
-	log.Fatal(errors.New("not implemented"), "not implemented")
-	return nil
+	if !isValid(repo) || repo.git == nil {
+		return errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	if msg == "" {
+		msg = DefaultCommitMessage
+	}
+	return repo.commitAll(msg)
 }
 
 func downloadTemplate() (file string, err error) {
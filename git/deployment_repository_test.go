@@ -1,25 +1,742 @@
 package git
 
 import (
+	"errors"
+	"fmt"
 	"github.com/Flaque/filet"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/suite"
 	"gosh/gitops"
+	"gosh/util"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 type DeploymentRepositorySuite struct {
 	suite.Suite
+	remote string
+	repo   *DeploymentRepository
 }
 
 func (suite *DeploymentRepositorySuite) SetupSuite() {
 	gitops.TestsSetupWorkingDir(suite.Suite)
 }
 
+// SetupTest Creates a bare 'remote' repository with a single commit and clones it into an empty working dir
+func (suite *DeploymentRepositorySuite) SetupTest() {
+	suite.remote = createTestRemote(suite.Suite)
+	suite.repo = cloneTestRemote(suite.Suite, suite.remote)
+}
+
 func (suite *DeploymentRepositorySuite) TearDownSuite() {
 	filet.CleanUp(suite.T())
 }
 
-//todo: we need mocking to test this...
+// cloneTestRemote Clones the remote into an empty working dir with test as git user
+func cloneTestRemote(suite suite.Suite, remote string) *DeploymentRepository {
+	util.Context.WorkingDir = filet.TmpDir(suite.T(), "")
+	repo := &DeploymentRepository{url: remote}
+	r := suite.Require()
+	r.Nil(repo.Clone())
+	cfg, err := repo.git.Config()
+	r.Nil(err)
+	cfg.User.Name = "test"
+	cfg.User.Email = "test@test"
+	r.Nil(repo.git.SetConfig(cfg))
+	return repo
+}
+
+func createTestRemote(suite suite.Suite) string {
+	r := suite.Require()
+	seed := filet.TmpDir(suite.T(), "")
+	seedRepo, err := git.PlainInit(seed, false)
+	r.Nil(err)
+	writeTestFile(suite, seed, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.0.0\n")
+	w, err := seedRepo.Worktree()
+	r.Nil(err)
+	r.Nil(w.AddWithOptions(&git.AddOptions{All: true}))
+	_, err = w.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@test", When: time.Now()}})
+	r.Nil(err)
+	remote := filet.TmpDir(suite.T(), "")
+	_, err = git.PlainInit(remote, true)
+	r.Nil(err)
+	_, err = seedRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}})
+	r.Nil(err)
+	r.Nil(seedRepo.Push(&git.PushOptions{}))
+	return remote
+}
+
+func writeTestFile(suite suite.Suite, dir string, path string, contents string) {
+	f := filepath.Join(dir, path)
+	_ = os.MkdirAll(filepath.Dir(f), 0755)
+	suite.Require().Nil(os.WriteFile(f, []byte(contents), 0644))
+}
+
+func (suite *DeploymentRepositorySuite) TestPush() {
+	r := suite.Require()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(suite.repo.Push("update alpha"))
+
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	commit, err := remote.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("update alpha", commit.Message)
+}
+
+// pushConcurrentChange Pushes a change to the remote from another clone, as a concurrent pipeline would
+func (suite *DeploymentRepositorySuite) pushConcurrentChange(path string, contents string) {
+	r := suite.Require()
+	dir := filet.TmpDir(suite.T(), "")
+	other, err := git.PlainClone(dir, false, &git.CloneOptions{URL: suite.remote})
+	r.Nil(err)
+	writeTestFile(suite.Suite, dir, path, contents)
+	w, err := other.Worktree()
+	r.Nil(err)
+	r.Nil(w.AddWithOptions(&git.AddOptions{All: true}))
+	_, err = w.Commit("concurrent change", &git.CommitOptions{Author: &object.Signature{Name: "other", Email: "other@test", When: time.Now()}})
+	r.Nil(err)
+	r.Nil(other.Push(&git.PushOptions{}))
+}
+
+func (suite *DeploymentRepositorySuite) TestPushRejected() {
+	r := suite.Require()
+	suite.pushConcurrentChange("inventory/classes/stages/beta.yml", "parameters:\n  beta: {}\n")
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Equal(PushRejectedErr, suite.repo.Push("update alpha"))
+}
+
+func (suite *DeploymentRepositorySuite) TestPushWithReplay() {
+	r := suite.Require()
+	defer func(delay time.Duration) { pushRetryBaseDelay = delay }(pushRetryBaseDelay)
+	pushRetryBaseDelay = time.Millisecond
+	stageFile := "inventory/classes/stages/alpha.yml"
+	suite.pushConcurrentChange(stageFile, "parameters:\n  alpha:\n    app1: 1.0.0\n    app2: 2.0.0\n")
+	//repositories cloned by git have packed refs
+	r.Nil(suite.repo.git.Storer.PackRefs())
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	replays := 0
+	replay := func() (string, error) {
+		replays++
+		data, err := os.ReadFile(filepath.Join(util.Context.WorkingDir, stageFile))
+		r.Nil(err)
+		r.Equal("parameters:\n  alpha:\n    app1: 1.0.0\n    app2: 2.0.0\n", string(data))
+		writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n    app2: 2.0.0\n")
+		return "update alpha", nil
+	}
+	r.Nil(suite.repo.PushWithReplay("update alpha", replay))
+	r.Equal(1, replays)
+
+	revision, err := suite.repo.FileAt(filepath.Join(util.Context.WorkingDir, stageFile), "HEAD")
+	r.Nil(err)
+	r.Equal("update alpha", revision.Message)
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	r.Equal(revision.Hash, head.Hash())
+	commit, err := remote.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal(1, commit.NumParents())
+	parent, err := commit.Parent(0)
+	r.Nil(err)
+	r.Equal("concurrent change", parent.Message)
+}
+
+func (suite *DeploymentRepositorySuite) TestIsPushRejected() {
+	r := suite.Require()
+	r.True(isPushRejected(git.ErrNonFastForwardUpdate))
+	r.True(isPushRejected(errors.New("command error on refs/heads/master: non-fast-forward")))
+	r.True(isPushRejected(errors.New("! [rejected] master -> master (fetch first)")))
+	r.False(isPushRejected(errors.New("command error on refs/heads/master: pre-receive hook declined")))
+	r.False(isPushRejected(errors.New("! [remote rejected] master -> master (protected branch hook declined)")))
+}
+
+func (suite *DeploymentRepositorySuite) TestPushWithReplayWithoutChanges() {
+	r := suite.Require()
+	defer func(delay time.Duration) { pushRetryBaseDelay = delay }(pushRetryBaseDelay)
+	pushRetryBaseDelay = time.Millisecond
+	stageFile := "inventory/classes/stages/alpha.yml"
+	suite.pushConcurrentChange(stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	//the remote already contains the change, replaying it changes nothing
+	r.Nil(suite.repo.PushWithReplay("update alpha", func() (string, error) {
+		writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+		return "update alpha", nil
+	}))
+
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	commit, err := remote.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("concurrent change", commit.Message)
+}
+
+func (suite *DeploymentRepositorySuite) TestCommit() {
+	r := suite.Require()
+	var repo Repository = suite.repo
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	remoteHead, err := remote.Head()
+	r.Nil(err)
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(repo.Commit("update alpha"))
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Nil(repo.Commit("nothing changed"))
+	unchanged, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Equal(head.Hash(), unchanged.Hash())
+	current, err := remote.Head()
+	r.Nil(err)
+	r.Equal(remoteHead.Hash(), current.Hash())
+
+	r.Nil(repo.Push(""))
+	current, err = remote.Head()
+	r.Nil(err)
+	r.Equal(head.Hash(), current.Hash())
+}
+
+func (suite *DeploymentRepositorySuite) TestPushWithReplayKeepsLocalCommits() {
+	r := suite.Require()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/beta.yml", "parameters:\n  beta: {}\n")
+	r.Nil(suite.repo.Commit("create beta"))
+	suite.pushConcurrentChange("inventory/classes/stages/gamma.yml", "parameters:\n  gamma: {}\n")
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	replay := func() (string, error) {
+		r.Fail("replay would discard the local commit")
+		return "", nil
+	}
+	r.Equal(PushRejectedErr, suite.repo.PushWithReplay("update alpha", replay))
+	_, err := os.Stat(filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/beta.yml"))
+	r.Nil(err)
+}
+
+func (suite *DeploymentRepositorySuite) TestPushBranch() {
+	r := suite.Require()
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Equal(NothingToPushErr, suite.repo.PushBranch("nothing changed", "gosh/review"))
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/beta.yml", "parameters:\n  beta: {}\n")
+	r.Nil(suite.repo.PushBranch("update alpha", "gosh/review"))
+
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	remoteHead, err := remote.Head()
+	r.Nil(err)
+	r.Equal(head.Hash(), remoteHead.Hash())
+	branch, err := remote.Reference(plumbing.NewBranchReferenceName("gosh/review"), false)
+	r.Nil(err)
+	commit, err := remote.CommitObject(branch.Hash())
+	r.Nil(err)
+	r.Equal("update alpha", commit.Message)
+	r.Equal(head.Hash(), commit.ParentHashes[0])
+
+	current, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Equal(head.Name(), current.Name())
+	r.Equal(head.Hash(), current.Hash())
+	_, err = suite.repo.git.Reference(plumbing.NewBranchReferenceName("gosh/review"), false)
+	r.NotNil(err)
+	data, err := os.ReadFile(filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/alpha.yml"))
+	r.Nil(err)
+	r.Equal("parameters:\n  alpha:\n    app1: 1.0.0\n", string(data))
+	_, err = os.Stat(filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/beta.yml"))
+	r.True(os.IsNotExist(err))
+
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.2.0\n")
+	r.Equal(PushRejectedErr, suite.repo.PushBranch("update alpha again", "gosh/review"))
+	_, err = suite.repo.git.Reference(plumbing.NewBranchReferenceName("gosh/review"), false)
+	r.Nil(err)
+	r.Equal(BranchExistsErr, suite.repo.PushBranch("update alpha again", "gosh/review"))
+}
+
+// pushTestBranch Pushes a new branch with a changed alpha stage to the remote
+func (suite *DeploymentRepositorySuite) pushTestBranch(branch string) {
+	r := suite.Require()
+	dir := filet.TmpDir(suite.T(), "")
+	other, err := git.PlainClone(dir, false, &git.CloneOptions{URL: suite.remote})
+	r.Nil(err)
+	w, err := other.Worktree()
+	r.Nil(err)
+	r.Nil(w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}))
+	writeTestFile(suite.Suite, dir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 2.0.0\n")
+	r.Nil(w.AddWithOptions(&git.AddOptions{All: true}))
+	_, err = w.Commit("update "+branch, &git.CommitOptions{Author: &object.Signature{Name: "other", Email: "other@test", When: time.Now()}})
+	r.Nil(err)
+	r.Nil(other.Push(&git.PushOptions{RefSpecs: []config.RefSpec{branchRefSpec(plumbing.NewBranchReferenceName(branch))}}))
+}
+
+func (suite *DeploymentRepositorySuite) TestCloneBranch() {
+	r := suite.Require()
+	suite.pushTestBranch("staging")
+	util.Config.Repository.Branch = "staging"
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	repo := cloneTestRemote(suite.Suite, suite.remote)
+	head, err := repo.git.Head()
+	r.Nil(err)
+	r.Equal(plumbing.NewBranchReferenceName("staging"), head.Name())
+	data, err := os.ReadFile(filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/alpha.yml"))
+	r.Nil(err)
+	r.Equal("parameters:\n  alpha:\n    app1: 2.0.0\n", string(data))
+}
+
+func (suite *DeploymentRepositorySuite) TestPullAndPushBranch() {
+	r := suite.Require()
+	suite.pushTestBranch("staging")
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	master, err := remote.Head()
+	r.Nil(err)
+	util.Config.Repository.Branch = "staging"
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Equal(WrongBranchErr, suite.repo.Push("update alpha on master"))
+	r.Equal(UncommittedChangesErr, suite.repo.Pull())
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Equal(plumbing.NewBranchReferenceName("master"), head.Name())
+	w, err := suite.repo.git.Worktree()
+	r.Nil(err)
+	r.Nil(w.Reset(&git.ResetOptions{Mode: git.HardReset}))
+
+	r.Nil(suite.repo.Pull())
+	head, err = suite.repo.git.Head()
+	r.Nil(err)
+	r.Equal(plumbing.NewBranchReferenceName("staging"), head.Name())
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 2.1.0\n")
+	r.Nil(suite.repo.Push("update alpha on staging"))
+
+	staging, err := remote.Reference(plumbing.NewBranchReferenceName("staging"), false)
+	r.Nil(err)
+	commit, err := remote.CommitObject(staging.Hash())
+	r.Nil(err)
+	r.Equal("update alpha on staging", commit.Message)
+	current, err := remote.Head()
+	r.Nil(err)
+	r.Equal(master.Hash(), current.Hash())
+}
+
+func (suite *DeploymentRepositorySuite) TestShallowClone() {
+	r := suite.Require()
+	defer func(delay time.Duration) { pushRetryBaseDelay = delay }(pushRetryBaseDelay)
+	pushRetryBaseDelay = time.Millisecond
+	stageFile := "inventory/classes/stages/alpha.yml"
+	suite.pushConcurrentChange(stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	util.Config.Repository.Depth = 1
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	repo := cloneTestRemote(suite.Suite, suite.remote)
+	r.True(repo.isShallow())
+	revisions, err := repo.FileHistory(stageFile)
+	r.Nil(err)
+	r.Len(revisions, 1)
+
+	suite.pushConcurrentChange(stageFile, "parameters:\n  alpha:\n    app1: 1.2.0\n")
+	suite.pushConcurrentChange(stageFile, "parameters:\n  alpha:\n    app1: 1.3.0\n")
+	r.Nil(repo.Pull())
+	r.Nil(repo.Pull())
+	data, err := os.ReadFile(filepath.Join(util.Context.WorkingDir, stageFile))
+	r.Nil(err)
+	r.Equal("parameters:\n  alpha:\n    app1: 1.3.0\n", string(data))
+
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.4.0\n")
+	r.Nil(repo.Push("update alpha"))
+	suite.pushConcurrentChange("inventory/classes/stages/beta.yml", "parameters:\n  beta: {}\n")
+	replay := func() (string, error) {
+		writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.5.0\n")
+		return "update alpha again", nil
+	}
+	_, _ = replay()
+	r.Nil(repo.PushWithReplay("update alpha again", replay))
+
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	commit, err := remote.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("update alpha again", commit.Message)
+	parent, err := commit.Parent(0)
+	r.Nil(err)
+	r.Equal("concurrent change", parent.Message)
+}
+
+func (suite *DeploymentRepositorySuite) TestSparseClone() {
+	r := suite.Require()
+	targetFile := "inventory/targets/dev.yml"
+	compiledFile := "compiled/dev/manifest.yml"
+	suite.pushConcurrentChange(targetFile, "classes:\n  - stages.alpha\n")
+	suite.pushConcurrentChange(compiledFile, "kind: Deployment\n")
+	util.Config.Repository.Sparse = true
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	repo := cloneTestRemote(suite.Suite, suite.remote)
+	r.True(repo.isSparse())
+	r.FileExists(filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/alpha.yml"))
+	r.FileExists(filepath.Join(util.Context.WorkingDir, targetFile))
+	r.NoFileExists(filepath.Join(util.Context.WorkingDir, compiledFile))
+
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(repo.Push("update alpha"))
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	commit, err := remote.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("update alpha", commit.Message)
+	_, err = commit.File(compiledFile)
+	r.Nil(err)
+
+	suite.pushConcurrentChange("inventory/classes/stages/beta.yml", "parameters:\n  beta: {}\n")
+	r.Nil(repo.Pull())
+	r.FileExists(filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/beta.yml"))
+	r.NoFileExists(filepath.Join(util.Context.WorkingDir, compiledFile))
+	w, err := repo.git.Worktree()
+	r.Nil(err)
+	status, err := repo.status(w)
+	r.Nil(err)
+	r.True(status.IsClean())
+}
+
+func (suite *DeploymentRepositorySuite) TestSparseCloneKeepsOtherFilesUpToDate() {
+	r := suite.Require()
+	compiledFile := "compiled/dev/manifest.yml"
+	suite.pushConcurrentChange(compiledFile, "replicas: 1\n")
+	util.Config.Repository.Sparse = true
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	repo := cloneTestRemote(suite.Suite, suite.remote)
+	//a file outside the sparse directories that is present anyway, e.g. written by another tool
+	writeTestFile(suite.Suite, util.Context.WorkingDir, compiledFile, "replicas: 1\n")
+
+	suite.pushConcurrentChange(compiledFile, "replicas: 2\n")
+	r.Nil(repo.Pull())
+	data, err := os.ReadFile(filepath.Join(util.Context.WorkingDir, compiledFile))
+	r.Nil(err)
+	r.Equal("replicas: 2\n", string(data))
+
+	//changes outside the sparse directories are never committed
+	writeTestFile(suite.Suite, util.Context.WorkingDir, compiledFile, "replicas: 3\n")
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(repo.Push("update alpha"))
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	commit, err := remote.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("update alpha", commit.Message)
+	file, err := commit.File(compiledFile)
+	r.Nil(err)
+	contents, err := file.Contents()
+	r.Nil(err)
+	r.Equal("replicas: 2\n", contents)
+}
+
+func (suite *DeploymentRepositorySuite) TestOffline() {
+	r := suite.Require()
+	stageFile := "inventory/classes/stages/alpha.yml"
+	suite.pushConcurrentChange(stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	util.Config.Repository.Offline = true
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	defer func(auth util.AuthConfig) { util.Config.Auth = auth }(util.Config.Auth)
+	util.Config.Auth = nil
+
+	repo, err := NewDeploymentRepository("", false)
+	r.Nil(err)
+	data, err := os.ReadFile(filepath.Join(util.Context.WorkingDir, stageFile))
+	r.Nil(err)
+	r.Equal("parameters:\n  alpha:\n    app1: 1.0.0\n", string(data))
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.2.0\n")
+	r.Equal(OfflineErr, repo.Push("update alpha"))
+	r.Nil(repo.Commit("update alpha"))
+	r.Equal(OfflineErr, repo.Pull())
+	_, err = repo.ResolveTag("product/2021.R1")
+	r.NotNil(err)
+
+	util.Config.Repository.Offline = false
+	repo, err = OpenLocalDeploymentRepository()
+	r.Nil(err)
+	revisions, err := repo.FileHistory(stageFile)
+	r.Nil(err)
+	r.Len(revisions, 2)
+	r.Equal("update alpha", revisions[0].Message)
+	r.Equal(OfflineErr, repo.Push("update alpha"))
+}
+
+func (suite *DeploymentRepositorySuite) TestCommitIdentity() {
+	r := suite.Require()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(suite.repo.Commit("update alpha"))
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	commit, err := suite.repo.git.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("test", commit.Author.Name)
+	r.Equal(util.DefaultCommitterName, commit.Committer.Name)
+
+	util.Config.Commits.Author = util.SignatureConfig{Name: "ci", Email: "ci@example.com"}
+	util.Config.Commits.Committer = util.SignatureConfig{Name: "deployer", Email: "deployer@example.com"}
+	defer func() { util.Config.Commits = util.CommitsConfig{} }()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.2.0\n")
+	r.Nil(suite.repo.Commit("update alpha again"))
+	head, err = suite.repo.git.Head()
+	r.Nil(err)
+	commit, err = suite.repo.git.CommitObject(head.Hash())
+	r.Nil(err)
+	r.Equal("ci <ci@example.com>", commit.Author.String())
+	r.Equal("deployer <deployer@example.com>", commit.Committer.String())
+}
+
+func (suite *DeploymentRepositorySuite) TestChangeEvents() {
+	r := suite.Require()
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	for _, version := range []string{"1.1.0", "1.2.0"} {
+		writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: "+version+"\n")
+		msg, err := CommitMessage(CommitInfo{Summary: "update app1", Operation: UpdateVersionOperation, App: "app1", Version: version, Stage: "alpha"})
+		r.Nil(err)
+		r.Nil(suite.repo.Commit(msg))
+	}
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "README.md", "manual change\n")
+	r.Nil(suite.repo.Commit("manual change"))
+
+	events, err := suite.repo.ChangeEvents("", "")
+	r.Nil(err)
+	r.Len(events, 2)
+	r.Equal("1.1.0", events[0].Version)
+	r.Equal("1.2.0", events[1].Version)
+	r.Equal(UpdateVersionOperation, events[1].Operation)
+	r.Equal("stage/alpha", events[1].TargetList)
+
+	events, err = suite.repo.ChangeEvents(head.Hash().String(), "HEAD~2")
+	r.Nil(err)
+	r.Len(events, 1)
+	r.Equal("1.1.0", events[0].Version)
+
+	_, err = suite.repo.ChangeEvents("unknown", "")
+	r.NotNil(err)
+}
+
+func (suite *DeploymentRepositorySuite) TestTag() {
+	r := suite.Require()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/releases/product/R1.yml", "parameters:\n  release_states:\n    R1: final\n")
+	r.Nil(suite.repo.Push("finalize R1"))
+	r.Nil(suite.repo.Tag("product/R1", "release product/R1"))
+
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	tag, err := remote.Tag("product/R1")
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	tagObject, err := remote.TagObject(tag.Hash())
+	r.Nil(err)
+	r.Equal(head.Hash(), tagObject.Target)
+
+	err = suite.repo.Tag("product/R1", "again")
+	r.Equal(git.ErrTagExists, err)
+}
+
+func (suite *DeploymentRepositorySuite) TestPushTags() {
+	r := suite.Require()
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(suite.repo.Commit("release R1"))
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Nil(suite.repo.CreateTag("product/R1", "release product/R1", head.Hash()))
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	_, err = remote.Tag("product/R1")
+	r.Equal(git.ErrTagNotFound, err)
+
+	//tags on commits that are not pushed yet are pushed with them, also by another command
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.2.0\n")
+	r.Nil(suite.repo.Commit("after release"))
+	suite.repo.tags = nil
+	r.Nil(suite.repo.Push(""))
+	tag, err := remote.Tag("product/R1")
+	r.Nil(err)
+	tagObject, err := remote.TagObject(tag.Hash())
+	r.Nil(err)
+	r.Equal(head.Hash(), tagObject.Target)
+}
+
+func (suite *DeploymentRepositorySuite) TestCheckoutTag() {
+	r := suite.Require()
+	stageFile := "inventory/classes/stages/alpha.yml"
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(suite.repo.Push("release"))
+	r.Nil(suite.repo.Tag("product/R1", "release product/R1"))
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 2.0.0\n")
+	r.Nil(suite.repo.Push("after release"))
+
+	dir := filet.TmpDir(suite.T(), "")
+	r.Nil(suite.repo.CheckoutTag("product/R1", dir))
+	data, err := os.ReadFile(filepath.Join(dir, stageFile))
+	r.Nil(err)
+	r.Equal("parameters:\n  alpha:\n    app1: 1.1.0\n", string(data))
+	data, err = os.ReadFile(filepath.Join(util.Context.WorkingDir, stageFile))
+	r.Nil(err)
+	r.Equal("parameters:\n  alpha:\n    app1: 2.0.0\n", string(data))
+
+	err = suite.repo.CheckoutTag("product/R1", dir)
+	r.Equal(WorkingDirNotEmptyErr, err)
+}
+
+func (suite *DeploymentRepositorySuite) TestResolveTagFromRemote() {
+	r := suite.Require()
+	remote, err := git.PlainOpen(suite.remote)
+	r.Nil(err)
+	head, err := remote.Head()
+	r.Nil(err)
+	_, err = remote.CreateTag("lightweight", head.Hash(), nil)
+	r.Nil(err)
+
+	hash, err := suite.repo.ResolveTag("lightweight")
+	r.Nil(err)
+	r.Equal(head.Hash(), hash)
+	_, err = suite.repo.ResolveTag("unknown")
+	r.Equal(git.ErrTagNotFound, err)
+}
+
+func (suite *DeploymentRepositorySuite) TestFileHistory() {
+	r := suite.Require()
+	stageFile := "inventory/classes/stages/alpha.yml"
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(suite.repo.Push("update alpha"))
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/beta.yml", "parameters:\n  beta: {}\n")
+	r.Nil(suite.repo.Push("unrelated"))
+	r.Nil(os.Remove(filepath.Join(util.Context.WorkingDir, stageFile)))
+	r.Nil(suite.repo.Push("remove alpha"))
+
+	revisions, err := suite.repo.FileHistory(filepath.Join(util.Context.WorkingDir, stageFile))
+	r.Nil(err)
+	r.Len(revisions, 3)
+	r.Equal("remove alpha", revisions[0].Message)
+	r.Nil(revisions[0].Contents)
+	r.Equal("update alpha", revisions[1].Message)
+	r.Equal("parameters:\n  alpha:\n    app1: 1.1.0\n", string(revisions[1].Contents))
+	r.Equal("initial commit", revisions[2].Message)
+	r.Equal("test", revisions[2].Author.Name)
+}
+
+func (suite *DeploymentRepositorySuite) TestFileAt() {
+	r := suite.Require()
+	stageFile := "inventory/classes/stages/alpha.yml"
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	writeTestFile(suite.Suite, util.Context.WorkingDir, stageFile, "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.Nil(suite.repo.Push("update alpha"))
+
+	revision, err := suite.repo.FileAt(stageFile, head.Hash().String()[:7])
+	r.Nil(err)
+	r.Equal(head.Hash(), revision.Hash)
+	r.Equal("parameters:\n  alpha:\n    app1: 1.0.0\n", string(revision.Contents))
+	revision, err = suite.repo.FileAt("inventory/classes/stages/unknown.yml", "HEAD")
+	r.Nil(err)
+	r.Nil(revision.Contents)
+	_, err = suite.repo.FileAt(stageFile, "0000000")
+	r.NotNil(err)
+}
+
+// createLargeTestRemote Creates a bare remote with many commits, each changing a stage and one of many files outside of
+// inventory/classes, like the compiled output of a deployment repository
+func createLargeTestRemote(b *testing.B, commits int, files int) string {
+	dir := b.TempDir()
+	remote, err := git.PlainInit(dir, true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	s := remote.Storer
+	store := func(o interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		obj := s.NewEncodedObject()
+		if err := o.Encode(obj); err != nil {
+			b.Fatal(err)
+		}
+		hash, err := s.SetEncodedObject(obj)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return hash
+	}
+	blob := func(contents string) plumbing.Hash {
+		obj := s.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		w, _ := obj.Writer()
+		_, _ = w.Write([]byte(contents))
+		_ = w.Close()
+		hash, err := s.SetEncodedObject(obj)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return hash
+	}
+	dirEntry := func(name string, entries ...object.TreeEntry) object.TreeEntry {
+		return object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: store(&object.Tree{Entries: entries})}
+	}
+	compiled := make([]object.TreeEntry, files)
+	for i := range compiled {
+		compiled[i] = object.TreeEntry{Name: fmt.Sprintf("app%05d.yml", i), Mode: filemode.Regular, Hash: blob(fmt.Sprintf("name: app%d\nversion: 0\n", i))}
+	}
+	var parents []plumbing.Hash
+	for i := 0; i < commits; i++ {
+		compiled[i%files].Hash = blob(fmt.Sprintf("name: app%d\nversion: %d\n", i%files, i))
+		stage := object.TreeEntry{Name: "alpha.yml", Mode: filemode.Regular, Hash: blob(fmt.Sprintf("parameters:\n  alpha:\n    app1: 1.0.%d\n", i))}
+		root := &object.Tree{Entries: []object.TreeEntry{
+			dirEntry("compiled", compiled...),
+			dirEntry("inventory", dirEntry("classes", dirEntry("stages", stage))),
+		}}
+		signature := object.Signature{Name: "test", Email: "test@test", When: time.Unix(int64(1600000000+i), 0)}
+		commit := &object.Commit{Author: signature, Committer: signature, Message: fmt.Sprintf("commit %d", i), TreeHash: store(root), ParentHashes: parents}
+		parents = []plumbing.Hash{store(commit)}
+	}
+	if err = s.SetReference(plumbing.NewHashReference(plumbing.Master, parents[0])); err != nil {
+		b.Fatal(err)
+	}
+	//remotes serve packed objects, packing thousands of loose objects for every clone would dominate the benchmark
+	if err = remote.RepackObjects(&git.RepackConfig{}); err != nil {
+		b.Fatal(err)
+	}
+	return dir
+}
+
+// BenchmarkClone Compares full, shallow and sparse clones of a deployment repository with a long history
+func BenchmarkClone(b *testing.B) {
+	remote := createLargeTestRemote(b, 2000, 1000)
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	for _, mode := range []struct {
+		name   string
+		config util.RepositoryConfig
+	}{
+		{"full", util.RepositoryConfig{}},
+		{"shallow", util.RepositoryConfig{Depth: 1}},
+		{"sparse", util.RepositoryConfig{Sparse: true}},
+		{"shallow-sparse", util.RepositoryConfig{Depth: 1, Sparse: true}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			util.Config.Repository = mode.config
+			for i := 0; i < b.N; i++ {
+				util.Context.WorkingDir = b.TempDir()
+				if err := (&DeploymentRepository{url: remote}).Clone(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
 
 func TestDeploymentRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(DeploymentRepositorySuite))
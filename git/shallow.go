@@ -0,0 +1,123 @@
+package git
+
+import (
+	"bytes"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"gosh/util"
+	"io"
+)
+
+// shallowStorage Reads the commits at the boundary of a shallow clone without their parents, as git does, so walking
+// the history stops at the fetched commits. go-git walks into the missing parents when pulling, pushing and reading the
+// log of shallow clones and fails with 'object not found'
+type shallowStorage struct {
+	*filesystem.Storage
+	shallow map[plumbing.Hash]bool
+}
+
+func (s *shallowStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.Storage.EncodedObject(t, h)
+	if err != nil || obj.Type() != plumbing.CommitObject || !s.isShallow(h) {
+		return obj, err
+	}
+	return graftCommit(obj)
+}
+
+func (s *shallowStorage) SetShallow(commits []plumbing.Hash) error {
+	s.shallow = nil
+	return s.Storage.SetShallow(commits)
+}
+
+func (s *shallowStorage) isShallow(h plumbing.Hash) bool {
+	if s.shallow == nil {
+		s.shallow = map[plumbing.Hash]bool{}
+		if commits, err := s.Storage.Shallow(); err == nil {
+			for _, commit := range commits {
+				s.shallow[commit] = true
+			}
+		}
+	}
+	return s.shallow[h]
+}
+
+// originalCommit Returns the commit as it is stored, with its parents, e.g. to verify its signature
+func (s *shallowStorage) originalCommit(commit *object.Commit) (*object.Commit, error) {
+	obj, err := s.Storage.EncodedObject(plumbing.CommitObject, commit.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return object.DecodeCommit(s, obj)
+}
+
+// graftedObject A commit object without parents, that keeps the hash of the original commit
+type graftedObject struct {
+	plumbing.MemoryObject
+	hash plumbing.Hash
+}
+
+func (o *graftedObject) Hash() plumbing.Hash {
+	return o.hash
+}
+
+// graftCommit Returns a copy of the commit object without the parent headers
+func graftCommit(obj plumbing.EncodedObject) (plumbing.EncodedObject, error) {
+	reader, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	grafted := &graftedObject{hash: obj.Hash()}
+	grafted.SetType(plumbing.CommitObject)
+	headers, message := contents, []byte(nil)
+	if end := bytes.Index(contents, []byte("\n\n")); end >= 0 {
+		headers, message = contents[:end+1], contents[end+1:]
+	}
+	for _, line := range bytes.SplitAfter(headers, []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("parent ")) {
+			_, _ = grafted.Write(line)
+		}
+	}
+	_, _ = grafted.Write(message)
+	return grafted, nil
+}
+
+// withShallowStorage Opens a shallow clone again on shallowStorage, other repositories are returned as they are
+func withShallowStorage(gitRepo *git.Repository) (*git.Repository, error) {
+	storage, ok := gitRepo.Storer.(*filesystem.Storage)
+	if !ok {
+		return gitRepo, nil
+	}
+	if commits, err := storage.Shallow(); err != nil || len(commits) == 0 {
+		return gitRepo, err
+	}
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	return git.Open(&shallowStorage{Storage: storage}, w.Filesystem)
+}
+
+// isShallow Returns true when the working dir is a shallow clone
+func (repo *DeploymentRepository) isShallow() bool {
+	_, ok := repo.git.Storer.(*shallowStorage)
+	return ok
+}
+
+// fetchDepth Returns the depth to fetch with, go-git only tells the remote which commits of a shallow clone have no
+// parents when a depth is set. Without it the remote can leave out objects the shallow clone does not have
+func (repo *DeploymentRepository) fetchDepth() int {
+	if !repo.isShallow() {
+		return 0
+	}
+	if depth := util.Config.Repository.Depth; depth > 0 {
+		return depth
+	}
+	return 1
+}
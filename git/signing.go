@@ -0,0 +1,435 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+	"gosh/log"
+	"gosh/util"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	sshSignatureNamespace = "git"
+	sshSignatureMagic     = "SSHSIG"
+	sshSignatureVersion   = 1
+	sshSignatureHeader    = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureFooter    = "-----END SSH SIGNATURE-----"
+	pgpPublicKeyHeader    = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	pgpPublicKeyFooter    = "-----END PGP PUBLIC KEY BLOCK-----"
+	sshSignatureLineWidth = 70
+)
+
+var (
+	InvalidSigningKeyErr     = errors.New("invalid commit signing key")
+	InvalidAllowedSignersErr = errors.New("invalid allowed signers file")
+	UnsignedCommitErr        = errors.New("commit is not signed")
+	InvalidSignatureErr      = errors.New("commit signature is invalid")
+	UnknownSignerErr         = errors.New("commit is not signed by an allowed signer")
+)
+
+// commitSigner Creates the armored signature stored in the gpgsig header of a commit
+type commitSigner interface {
+	sign(payload []byte) (string, error)
+}
+
+// newCommitSigner Loads the configured signing key, returns nil when commits are not signed
+func newCommitSigner(cfg util.SigningConfig) (commitSigner, error) {
+	if cfg.Type == 0 {
+		return nil, nil
+	}
+	key, err := ioutil.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, log.Errf(err, "Commit signing key %s could not be read", cfg.KeyFile)
+	}
+	switch cfg.Type {
+	case util.SshSigning:
+		return newSshSigner(key, decodeSecret(cfg.KeyPass))
+	case util.OpenPgpSigning:
+		return newOpenPgpSigner(key, decodeSecret(cfg.KeyPass))
+	}
+	return nil, util.UnsupportedSigningTypeErr
+}
+
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+func newSshSigner(key []byte, pass string) (*sshSigner, error) {
+	var signer ssh.Signer
+	var err error
+	if pass != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(pass))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, log.Errf(InvalidSigningKeyErr, "Unable to load and decrypt SSH signing key: %v", err)
+	}
+	return &sshSigner{signer: signer}, nil
+}
+
+// sshSignedData The data signed in the SSH signature format, see PROTOCOL.sshsig of OpenSSH
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// sshSignatureBlob The contents of an armored SSH signature, see PROTOCOL.sshsig of OpenSSH
+type sshSignatureBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+func (s *sshSigner) sign(payload []byte) (string, error) {
+	hash := sha512.Sum512(payload)
+	signed := sshSignedData{Namespace: sshSignatureNamespace, HashAlgorithm: "sha512", Hash: hash[:]}
+	message := append([]byte(sshSignatureMagic), ssh.Marshal(signed)...)
+	publicKey := s.signer.PublicKey()
+	var signature *ssh.Signature
+	var err error
+	if algorithmSigner, ok := s.signer.(ssh.AlgorithmSigner); ok && publicKey.Type() == ssh.KeyAlgoRSA {
+		//git rejects ssh-rsa signatures, they use SHA-1
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, message, ssh.SigAlgoRSASHA2512)
+	} else {
+		signature, err = s.signer.Sign(rand.Reader, message)
+	}
+	if err != nil {
+		return "", log.Errf(err, "Could not sign commit with SSH key %s", ssh.FingerprintSHA256(publicKey))
+	}
+	blob := sshSignatureBlob{
+		Version:       sshSignatureVersion,
+		PublicKey:     publicKey.Marshal(),
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: signed.HashAlgorithm,
+		Signature:     ssh.Marshal(signature),
+	}
+	encoded := base64.StdEncoding.EncodeToString(append([]byte(sshSignatureMagic), ssh.Marshal(blob)...))
+	lines := []string{sshSignatureHeader}
+	for len(encoded) > sshSignatureLineWidth {
+		lines = append(lines, encoded[:sshSignatureLineWidth])
+		encoded = encoded[sshSignatureLineWidth:]
+	}
+	lines = append(lines, encoded, sshSignatureFooter)
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+type openPgpSigner struct {
+	entity *openpgp.Entity
+}
+
+func newOpenPgpSigner(key []byte, pass string) (*openPgpSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(key))
+	}
+	if err != nil {
+		return nil, log.Errf(InvalidSigningKeyErr, "Unable to load OpenPGP signing key: %v", err)
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			if err = entity.PrivateKey.Decrypt([]byte(pass)); err != nil {
+				return nil, log.Errf(InvalidSigningKeyErr, "Unable to decrypt OpenPGP signing key: %v", err)
+			}
+		}
+		for _, subkey := range entity.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				if err = subkey.PrivateKey.Decrypt([]byte(pass)); err != nil {
+					return nil, log.Errf(InvalidSigningKeyErr, "Unable to decrypt OpenPGP signing subkey: %v", err)
+				}
+			}
+		}
+		return &openPgpSigner{entity: entity}, nil
+	}
+	return nil, log.Errf(InvalidSigningKeyErr, "No OpenPGP private key found")
+}
+
+func (s *openPgpSigner) sign(payload []byte) (string, error) {
+	signature := new(bytes.Buffer)
+	if err := openpgp.ArmoredDetachSign(signature, s.entity, bytes.NewReader(payload), nil); err != nil {
+		return "", log.Errf(err, "Could not sign commit with OpenPGP key %X", s.entity.PrimaryKey.Fingerprint)
+	}
+	return signature.String(), nil
+}
+
+// signHead Replaces the HEAD commit by the same commit signed by the signer, nothing changes when signer is nil
+func (repo *DeploymentRepository) signHead(signer commitSigner) error {
+	if signer == nil {
+		return nil
+	}
+	head, err := repo.git.Head()
+	if err != nil {
+		return log.Errf(err, "Could not resolve HEAD")
+	}
+	commit, err := repo.git.CommitObject(head.Hash())
+	if err != nil {
+		return log.Errf(err, "Could not read commit %s", head.Hash())
+	}
+	payload, err := signedPayload(commit)
+	if err != nil {
+		return err
+	}
+	if commit.PGPSignature, err = signer.sign(payload); err != nil {
+		return err
+	}
+	signed := repo.git.Storer.NewEncodedObject()
+	if err = commit.Encode(signed); err != nil {
+		return log.Errf(err, "Could not encode signed commit")
+	}
+	hash, err := repo.git.Storer.SetEncodedObject(signed)
+	if err != nil {
+		return log.Errf(err, "Could not store signed commit")
+	}
+	log.Debugf("Signed commit %s, replaced %s", hash, head.Hash())
+	return repo.git.Storer.SetReference(plumbing.NewHashReference(head.Name(), hash))
+}
+
+// signedPayload Returns the commit as it is signed, without its signature
+func signedPayload(commit *object.Commit) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return nil, log.Errf(err, "Could not encode commit %s", commit.Hash)
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// AllowedSigners The SSH and OpenPGP keys that are trusted to sign commits
+type AllowedSigners struct {
+	ssh     []allowedSshSigner
+	openPgp openpgp.EntityList
+}
+
+type allowedSshSigner struct {
+	principals string
+	key        ssh.PublicKey
+}
+
+// LoadAllowedSigners Reads an allowed signers file, see ParseAllowedSigners
+func LoadAllowedSigners(path string) (*AllowedSigners, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, log.Errf(err, "Allowed signers file %s could not be read", path)
+	}
+	return ParseAllowedSigners(data)
+}
+
+// ParseAllowedSigners Parses SSH keys in the git allowed signers format, 'PRINCIPALS [OPTIONS] KEYTYPE KEY [COMMENT]',
+// and armored OpenPGP public key blocks. Keys restricted to namespaces other than git are ignored
+func ParseAllowedSigners(data []byte) (*AllowedSigners, error) {
+	signers := &AllowedSigners{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var block []string
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case block != nil:
+			block = append(block, line)
+			if line == pgpPublicKeyFooter {
+				entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(strings.Join(block, "\n")))
+				if err != nil {
+					return nil, log.Errf(InvalidAllowedSignersErr, "Invalid OpenPGP public key ending on line %d: %v", lineNumber, err)
+				}
+				signers.openPgp = append(signers.openPgp, entities...)
+				block = nil
+			}
+		case line == pgpPublicKeyHeader:
+			block = []string{line}
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			fields := strings.SplitN(line, " ", 2)
+			if len(fields) < 2 {
+				return nil, log.Errf(InvalidAllowedSignersErr, "Missing key on line %d", lineNumber)
+			}
+			key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(fields[1]))
+			if err != nil {
+				return nil, log.Errf(InvalidAllowedSignersErr, "Invalid SSH key on line %d: %v", lineNumber, err)
+			}
+			if allowsGitNamespace(options) {
+				signers.ssh = append(signers.ssh, allowedSshSigner{principals: fields[0], key: key})
+			}
+		}
+	}
+	if block != nil {
+		return nil, log.Errf(InvalidAllowedSignersErr, "OpenPGP public key block is not terminated")
+	}
+	return signers, scanner.Err()
+}
+
+// allowsGitNamespace Returns false when the namespaces option of an allowed signer does not include git
+func allowsGitNamespace(options []string) bool {
+	for _, option := range options {
+		if strings.HasPrefix(strings.ToLower(option), "namespaces=") {
+			for _, namespace := range strings.Split(strings.Trim(option[len("namespaces="):], `"`), ",") {
+				if strings.TrimSpace(namespace) == sshSignatureNamespace {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// CommitVerification The result of verifying the signature of a commit, Err is nil when the commit is signed by an
+// allowed signer
+type CommitVerification struct {
+	Commit    plumbing.Hash
+	Committer object.Signature
+	Summary   string
+	Type      util.SigningType
+	//Signer the principals of the SSH key or the identity of the OpenPGP key that signed the commit
+	Signer string
+	//Key the fingerprint of the key that signed the commit
+	Key string
+	Err error
+}
+
+// Verify Checks that the commit is signed by one of the allowed signers
+func (signers *AllowedSigners) Verify(commit *object.Commit) CommitVerification {
+	result := CommitVerification{
+		Commit:    commit.Hash,
+		Committer: commit.Committer,
+		Summary:   strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0],
+	}
+	if commit.PGPSignature == "" {
+		result.Err = UnsignedCommitErr
+		return result
+	}
+	payload, err := signedPayload(commit)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if strings.HasPrefix(strings.TrimSpace(commit.PGPSignature), sshSignatureHeader) {
+		result.Type = util.SshSigning
+		signers.verifySsh(payload, commit.PGPSignature, &result)
+	} else {
+		result.Type = util.OpenPgpSigning
+		signers.verifyOpenPgp(payload, commit.PGPSignature, &result)
+	}
+	return result
+}
+
+func (signers *AllowedSigners) verifySsh(payload []byte, armored string, result *CommitVerification) {
+	key, signature, hashAlgorithm, err := parseSshSignature(armored)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.Key = ssh.FingerprintSHA256(key)
+	var hash []byte
+	switch hashAlgorithm {
+	case "sha512":
+		sum := sha512.Sum512(payload)
+		hash = sum[:]
+	case "sha256":
+		sum := sha256.Sum256(payload)
+		hash = sum[:]
+	default:
+		result.Err = log.Errf(InvalidSignatureErr, "unsupported SSH signature hash algorithm %s", hashAlgorithm)
+		return
+	}
+	signed := sshSignedData{Namespace: sshSignatureNamespace, HashAlgorithm: hashAlgorithm, Hash: hash}
+	if err = key.Verify(append([]byte(sshSignatureMagic), ssh.Marshal(signed)...), signature); err != nil {
+		result.Err = InvalidSignatureErr
+		return
+	}
+	for _, signer := range signers.ssh {
+		if bytes.Equal(signer.key.Marshal(), key.Marshal()) {
+			result.Signer = signer.principals
+			return
+		}
+	}
+	result.Err = UnknownSignerErr
+}
+
+// parseSshSignature Returns the public key, signature and hash algorithm of an armored SSH signature in the git namespace
+func parseSshSignature(armored string) (ssh.PublicKey, *ssh.Signature, string, error) {
+	encoded := strings.TrimSpace(armored)
+	encoded = strings.TrimSuffix(strings.TrimPrefix(encoded, sshSignatureHeader), sshSignatureFooter)
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil || !bytes.HasPrefix(data, []byte(sshSignatureMagic)) {
+		return nil, nil, "", log.Errf(InvalidSignatureErr, "malformed SSH signature")
+	}
+	blob := sshSignatureBlob{}
+	if err = ssh.Unmarshal(data[len(sshSignatureMagic):], &blob); err != nil {
+		return nil, nil, "", log.Errf(InvalidSignatureErr, "malformed SSH signature: %v", err)
+	}
+	if blob.Version != sshSignatureVersion || blob.Namespace != sshSignatureNamespace {
+		return nil, nil, "", log.Errf(InvalidSignatureErr, "unsupported SSH signature version %d or namespace %s", blob.Version, blob.Namespace)
+	}
+	key, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, nil, "", log.Errf(InvalidSignatureErr, "invalid public key in SSH signature: %v", err)
+	}
+	signature := &ssh.Signature{}
+	if err = ssh.Unmarshal(blob.Signature, signature); err != nil {
+		return nil, nil, "", log.Errf(InvalidSignatureErr, "malformed SSH signature: %v", err)
+	}
+	return key, signature, blob.HashAlgorithm, nil
+}
+
+func (signers *AllowedSigners) verifyOpenPgp(payload []byte, armored string, result *CommitVerification) {
+	entity, err := openpgp.CheckArmoredDetachedSignature(signers.openPgp, bytes.NewReader(payload), strings.NewReader(armored), nil)
+	if err == pgperrors.ErrUnknownIssuer {
+		result.Err = UnknownSignerErr
+		return
+	}
+	if err != nil {
+		result.Err = InvalidSignatureErr
+		return
+	}
+	result.Key = fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		result.Signer = identity.Name
+	}
+}
+
+// VerifyCommits Verifies the signatures of the commits reachable from 'to' but not from 'from', oldest first. Both are
+// revisions like for ChangeEvents
+func (repo *DeploymentRepository) VerifyCommits(from string, to string, signers *AllowedSigners) ([]CommitVerification, error) {
+	commits, err := repo.commitRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	verifications := make([]CommitVerification, 0, len(commits))
+	for _, commit := range commits {
+		//the commits at the boundary of a shallow clone are read without parents, which are part of the signed payload
+		if storage, ok := repo.git.Storer.(*shallowStorage); ok {
+			original, err := storage.originalCommit(commit)
+			if err != nil {
+				return nil, log.Errf(err, "Could not read commit %s", commit.Hash)
+			}
+			commit = original
+		}
+		verifications = append(verifications, signers.Verify(commit))
+	}
+	return verifications, nil
+}
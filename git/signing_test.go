@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/Flaque/filet"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/ssh"
+	"gosh/gitops"
+	"gosh/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type SigningSuite struct {
+	suite.Suite
+	repo *DeploymentRepository
+	head string
+}
+
+func (suite *SigningSuite) SetupSuite() {
+	gitops.TestsSetupWorkingDir(suite.Suite)
+}
+
+func (suite *SigningSuite) SetupTest() {
+	suite.repo = cloneTestRemote(suite.Suite, createTestRemote(suite.Suite))
+	head, err := suite.repo.git.Head()
+	suite.Require().Nil(err)
+	suite.head = head.Hash().String()
+}
+
+func (suite *SigningSuite) TearDownTest() {
+	util.Config.Signing = util.SigningConfig{}
+}
+
+func (suite *SigningSuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+// newSshKey Writes a new ed25519 private key to a file and returns the file and the public key in authorized keys format
+func (suite *SigningSuite) newSshKey() (string, string) {
+	r := suite.Require()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	r.Nil(err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	r.Nil(err)
+	file := filepath.Join(filet.TmpDir(suite.T(), ""), "id_ed25519")
+	r.Nil(os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	sshPublic, err := ssh.NewPublicKey(public)
+	r.Nil(err)
+	return file, string(bytes.TrimSpace(ssh.MarshalAuthorizedKey(sshPublic)))
+}
+
+// newOpenPgpKey Writes a new armored OpenPGP private key to a file and returns the file and the armored public key
+func (suite *SigningSuite) newOpenPgpKey() (string, string) {
+	r := suite.Require()
+	entity, err := openpgp.NewEntity("test", "", "test@test", nil)
+	r.Nil(err)
+	private := new(bytes.Buffer)
+	w, err := armor.Encode(private, openpgp.PrivateKeyType, nil)
+	r.Nil(err)
+	r.Nil(entity.SerializePrivate(w, nil))
+	r.Nil(w.Close())
+	public := new(bytes.Buffer)
+	w, err = armor.Encode(public, openpgp.PublicKeyType, nil)
+	r.Nil(err)
+	r.Nil(entity.Serialize(w))
+	r.Nil(w.Close())
+	file := filepath.Join(filet.TmpDir(suite.T(), ""), "private.asc")
+	r.Nil(os.WriteFile(file, private.Bytes(), 0600))
+	return file, public.String()
+}
+
+func (suite *SigningSuite) commit(version string) {
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: "+version+"\n")
+	suite.Require().Nil(suite.repo.Commit("update alpha to " + version))
+}
+
+func (suite *SigningSuite) TestSshSigning() {
+	r := suite.Require()
+	keyFile, publicKey := suite.newSshKey()
+	_, otherKey := suite.newSshKey()
+	suite.commit("1.1.0")
+	util.Config.Signing = util.SigningConfig{Type: util.SshSigning, KeyFile: keyFile}
+	suite.commit("1.2.0")
+	r.Nil(suite.repo.Push(""))
+
+	signers, err := ParseAllowedSigners([]byte("# deployers\ndeployer@example.com,ci@example.com " + publicKey + " ci key\n"))
+	r.Nil(err)
+	verifications, err := suite.repo.VerifyCommits(suite.head, "", signers)
+	r.Nil(err)
+	r.Len(verifications, 2)
+	r.Equal(UnsignedCommitErr, verifications[0].Err)
+	r.Nil(verifications[1].Err)
+	r.Equal(util.SshSigning, verifications[1].Type)
+	r.Equal("deployer@example.com,ci@example.com", verifications[1].Signer)
+	r.Equal("update alpha to 1.2.0", verifications[1].Summary)
+
+	signers, err = ParseAllowedSigners([]byte("deployer@example.com " + otherKey + "\n"))
+	r.Nil(err)
+	verifications, err = suite.repo.VerifyCommits("HEAD~1", "HEAD", signers)
+	r.Nil(err)
+	r.Len(verifications, 1)
+	r.Equal(UnknownSignerErr, verifications[0].Err)
+	r.NotEmpty(verifications[0].Key)
+}
+
+func (suite *SigningSuite) TestOpenPgpSigning() {
+	r := suite.Require()
+	keyFile, publicKey := suite.newOpenPgpKey()
+	util.Config.Signing = util.SigningConfig{Type: util.OpenPgpSigning, KeyFile: keyFile}
+	suite.commit("1.1.0")
+
+	signers, err := ParseAllowedSigners([]byte(publicKey))
+	r.Nil(err)
+	verifications, err := suite.repo.VerifyCommits(suite.head, "", signers)
+	r.Nil(err)
+	r.Len(verifications, 1)
+	r.Nil(verifications[0].Err)
+	r.Equal(util.OpenPgpSigning, verifications[0].Type)
+	r.Equal("test <test@test>", verifications[0].Signer)
+
+	_, otherKey := suite.newOpenPgpKey()
+	signers, err = ParseAllowedSigners([]byte(otherKey))
+	r.Nil(err)
+	verifications, err = suite.repo.VerifyCommits(suite.head, "", signers)
+	r.Nil(err)
+	r.Equal(UnknownSignerErr, verifications[0].Err)
+}
+
+func (suite *SigningSuite) TestVerifyShallowClone() {
+	r := suite.Require()
+	keyFile, publicKey := suite.newSshKey()
+	util.Config.Signing = util.SigningConfig{Type: util.SshSigning, KeyFile: keyFile}
+	suite.commit("1.1.0")
+	r.Nil(suite.repo.Push(""))
+	util.Config.Repository.Depth = 1
+	defer func() { util.Config.Repository = util.RepositoryConfig{} }()
+	repo := cloneTestRemote(suite.Suite, suite.repo.url)
+	r.True(repo.isShallow())
+
+	signers, err := ParseAllowedSigners([]byte("deployer@example.com " + publicKey + "\n"))
+	r.Nil(err)
+	verifications, err := repo.VerifyCommits("", "", signers)
+	r.Nil(err)
+	r.Len(verifications, 1)
+	r.Nil(verifications[0].Err)
+}
+
+func (suite *SigningSuite) TestInvalidSigningKey() {
+	r := suite.Require()
+	util.Config.Signing = util.SigningConfig{Type: util.SshSigning, KeyFile: filepath.Join(util.Context.WorkingDir, "missing")}
+	writeTestFile(suite.Suite, util.Context.WorkingDir, "inventory/classes/stages/alpha.yml", "parameters:\n  alpha:\n    app1: 1.1.0\n")
+	r.NotNil(suite.repo.Commit("update alpha"))
+	head, err := suite.repo.git.Head()
+	r.Nil(err)
+	r.Equal(suite.head, head.Hash().String())
+}
+
+func (suite *SigningSuite) TestParseAllowedSigners() {
+	r := suite.Require()
+	_, publicKey := suite.newSshKey()
+	signers, err := ParseAllowedSigners([]byte(`ci@example.com namespaces="file" ` + publicKey + "\n" +
+		`deployer@example.com namespaces="file,git" ` + publicKey + "\n"))
+	r.Nil(err)
+	r.Len(signers.ssh, 1)
+	r.Equal("deployer@example.com", signers.ssh[0].principals)
+
+	_, err = ParseAllowedSigners([]byte("deployer@example.com\n"))
+	r.Equal(InvalidAllowedSignersErr, err)
+	_, err = ParseAllowedSigners([]byte("deployer@example.com ssh-ed25519 invalid\n"))
+	r.Equal(InvalidAllowedSignersErr, err)
+	_, err = ParseAllowedSigners([]byte(pgpPublicKeyHeader + "\n"))
+	r.Equal(InvalidAllowedSignersErr, err)
+}
+
+func TestSigningSuite(t *testing.T) {
+	suite.Run(t, new(SigningSuite))
+}
@@ -0,0 +1,177 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gosh/log"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	goshConfigSection    = "gosh"
+	sparseCheckoutOption = "sparse"
+)
+
+// sparseCheckoutDirs the only directories checked out in a sparse clone, the inventory and the gosh app templates
+// contain everything gosh reads and changes, compiled output and kapitan components are left out
+var sparseCheckoutDirs = []string{"inventory/", ".gosh/"}
+
+// isSparsePath Returns true when the slash separated path is checked out in a sparse clone
+func isSparsePath(p string) bool {
+	for _, dir := range sparseCheckoutDirs {
+		if strings.HasPrefix(p, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSparse Returns true when the working dir is a sparse clone, this is stored in the git config of the working dir
+func (repo *DeploymentRepository) isSparse() bool {
+	cfg, err := repo.git.Config()
+	return err == nil && cfg.Raw.Section(goshConfigSection).Option(sparseCheckoutOption) == "true"
+}
+
+// enableSparseCheckout Marks the working dir as sparse clone in its git config
+func (repo *DeploymentRepository) enableSparseCheckout() error {
+	cfg, err := repo.git.Config()
+	if err != nil {
+		return log.Errf(err, "Could not read git config of working dir")
+	}
+	cfg.Raw.Section(goshConfigSection).SetOption(sparseCheckoutOption, "true")
+	if err = repo.git.SetConfig(cfg); err != nil {
+		return log.Errf(err, "Could not write git config of working dir")
+	}
+	return nil
+}
+
+// sparseClone Marks the new clone as sparse and checks out the sparse directories of HEAD
+func (repo *DeploymentRepository) sparseClone() error {
+	if err := repo.enableSparseCheckout(); err != nil {
+		return err
+	}
+	head, err := repo.git.Head()
+	if err != nil {
+		return log.Errf(err, "Could not resolve HEAD")
+	}
+	return repo.checkoutSparse(head.Hash())
+}
+
+// checkoutSparse Sets the index to the tree of the commit and writes only the files in the sparse directories to the
+// working dir, discarding changes to them. go-git cannot write the skip-worktree flag git uses for sparse checkouts, the
+// other files are kept in the index without being checked out and are left out of the status instead. Other files that
+// are present in the working dir anyway are updated or removed as well, so they never hold stale contents
+func (repo *DeploymentRepository) checkoutSparse(hash plumbing.Hash) error {
+	commit, err := repo.git.CommitObject(hash)
+	if err != nil {
+		return log.Errf(err, "Could not read commit %s", hash)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return log.Errf(err, "Could not read tree of commit %s", hash)
+	}
+	w, err := repo.git.Worktree()
+	if err != nil {
+		return log.Errf(err, "Error accessing working tree in working dir")
+	}
+	previous, err := repo.git.Storer.Index()
+	if err != nil {
+		return log.Errf(err, "Could not read index of working dir")
+	}
+	idx := &index.Index{Version: 2}
+	files := map[string]bool{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return log.Errf(err, "Could not read tree of commit %s", hash)
+		}
+		if entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule {
+			continue
+		}
+		indexEntry := &index.Entry{Name: name, Hash: entry.Hash, Mode: entry.Mode}
+		if isSparsePath(name) || existsInWorktree(w, name) {
+			if err = writeTreeFile(w, tree, name, indexEntry); err != nil {
+				return log.Errf(err, "Could not check out %s", name)
+			}
+			files[name] = true
+		}
+		idx.Entries = append(idx.Entries, indexEntry)
+	}
+	for _, entry := range previous.Entries {
+		if !files[entry.Name] && (isSparsePath(entry.Name) || existsInWorktree(w, entry.Name)) {
+			if err = w.Filesystem.Remove(entry.Name); err != nil && !os.IsNotExist(err) {
+				return log.Errf(err, "Could not remove %s", entry.Name)
+			}
+		}
+	}
+	if err = repo.git.Storer.SetIndex(idx); err != nil {
+		return log.Errf(err, "Could not write index of working dir")
+	}
+	return nil
+}
+
+func existsInWorktree(w *git.Worktree, name string) bool {
+	_, err := w.Filesystem.Lstat(name)
+	return err == nil
+}
+
+// writeTreeFile Writes a file of the tree to the worktree and records its size and modification time in the entry
+func writeTreeFile(w *git.Worktree, tree *object.Tree, name string, entry *index.Entry) error {
+	file, err := tree.File(name)
+	if err != nil {
+		return err
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return err
+	}
+	if err = w.Filesystem.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	_ = w.Filesystem.Remove(name)
+	if entry.Mode == filemode.Symlink {
+		err = w.Filesystem.Symlink(contents, name)
+	} else {
+		mode, _ := entry.Mode.ToOSFileMode()
+		var f io.WriteCloser
+		if f, err = w.Filesystem.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()); err == nil {
+			_, err = io.WriteString(f, contents)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if info, err := w.Filesystem.Lstat(name); err == nil {
+		entry.ModifiedAt = info.ModTime()
+		entry.Size = uint32(info.Size())
+	}
+	return nil
+}
+
+// status Returns the status of the worktree, leaving out all files outside the sparse directories in a sparse clone,
+// gosh never changes them and committing them could revert changes made in the meantime
+func (repo *DeploymentRepository) status(w *git.Worktree) (git.Status, error) {
+	status, err := w.Status()
+	if err != nil || !repo.isSparse() {
+		return status, err
+	}
+	for p := range status {
+		if !isSparsePath(p) {
+			delete(status, p)
+		}
+	}
+	return status, nil
+}
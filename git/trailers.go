@@ -0,0 +1,276 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gosh/log"
+	"regexp"
+	"strings"
+)
+
+// Operation The kind of change a gosh commit made, recorded in the Gosh-Operation trailer
+type Operation int
+
+const (
+	CreateOperation Operation = iota + 1
+	UpdateOperation
+	UpdateVersionOperation
+	PromoteOperation
+	RollbackOperation
+	DeleteOperation
+	MoveOperation
+	RenameOperation
+	SetStateOperation
+	FinalizeOperation
+	ImportOperation
+)
+
+const (
+	OperationTrailer   = "Gosh-Operation"
+	TargetListTrailer  = "Gosh-Target-List"
+	ResourceTrailer    = "Gosh-Resource"
+	AppTrailer         = "Gosh-App"
+	FromVersionTrailer = "Gosh-From-Version"
+	VersionTrailer     = "Gosh-Version"
+	ForcedTrailer      = "Gosh-Forced"
+	BuildUrlTrailer    = "Gosh-Build-Url"
+)
+
+var (
+	UnsupportedOperationErr = errors.New("unsupported gosh operation")
+	trailerRegexp           = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+)
+
+// String Returns the name of the operation, empty for the zero value and unknown operations
+func (o Operation) String() string {
+	if o < CreateOperation || o > ImportOperation {
+		return ""
+	}
+	return [...]string{"create", "update", "update-version", "promote", "rollback", "delete", "move", "rename", "set-state", "finalize", "import"}[o-1]
+}
+
+func NewOperation(value string) (Operation, error) {
+	for o := CreateOperation; o <= ImportOperation; o++ {
+		if o.String() == strings.ToLower(strings.TrimSpace(value)) {
+			return o, nil
+		}
+	}
+	return 0, UnsupportedOperationErr
+}
+
+// Trailer A 'Key: value' line in the last paragraph of a commit message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// AppVersionChange The version change of a single app in a commit
+type AppVersionChange struct {
+	App  string
+	From string
+	To   string
+}
+
+// ChangeEvent A change made by gosh, parsed from the trailers of a commit. Commits that change the versions of
+// multiple apps result in an event per app
+type ChangeEvent struct {
+	Commit      plumbing.Hash
+	Author      object.Signature
+	Operation   Operation
+	TargetList  string
+	Resource    string
+	App         string
+	FromVersion string
+	Version     string
+	Forced      bool
+	BuildUrl    string
+}
+
+// ParseTrailers Returns the trailers of a commit message in order, a message has trailers when every line of its last
+// paragraph is a trailer
+func ParseTrailers(msg string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		match := trailerRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: match[1], Value: strings.TrimSpace(match[2])})
+	}
+	return trailers
+}
+
+// trailers Returns the gosh trailers describing the change, the operation, target list, resource and apps are only
+// recorded for changes with an operation
+func (info CommitInfo) trailers() []Trailer {
+	var trailers []Trailer
+	if info.Operation != 0 {
+		trailers = append(trailers, Trailer{Key: OperationTrailer, Value: info.Operation.String()})
+		if list := info.targetList(); list != "" {
+			trailers = append(trailers, Trailer{Key: TargetListTrailer, Value: list})
+		}
+		if info.Resource != "" {
+			trailers = append(trailers, Trailer{Key: ResourceTrailer, Value: info.Resource})
+		}
+		changes := info.Changes
+		if len(changes) == 0 && info.App != "" {
+			changes = []AppVersionChange{{App: info.App, From: info.FromVersion, To: info.Version}}
+		}
+		for _, change := range changes {
+			trailers = append(trailers, Trailer{Key: AppTrailer, Value: change.App})
+			if change.From != "" {
+				trailers = append(trailers, Trailer{Key: FromVersionTrailer, Value: change.From})
+			}
+			if change.To != "" {
+				trailers = append(trailers, Trailer{Key: VersionTrailer, Value: change.To})
+			}
+		}
+	}
+	if info.Forced {
+		trailers = append(trailers, Trailer{Key: ForcedTrailer, Value: "true"})
+	}
+	if info.BuildUrl != "" {
+		trailers = append(trailers, Trailer{Key: BuildUrlTrailer, Value: info.BuildUrl})
+	}
+	return trailers
+}
+
+// targetList Returns the stage, release or target the change was made to, e.g. 'stage/dev' or 'release/product/2021.R1'
+func (info CommitInfo) targetList() string {
+	switch {
+	case info.Stage != "":
+		return "stage/" + info.Stage
+	case info.Release != "":
+		return "release/" + info.Release
+	case info.Target != "":
+		return "target/" + info.Target
+	}
+	return ""
+}
+
+func formatTrailers(trailers []Trailer) string {
+	lines := make([]string, len(trailers))
+	for i, trailer := range trailers {
+		lines[i] = fmt.Sprintf("%s: %s", trailer.Key, trailer.Value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseChangeEvents Returns the changes recorded in the gosh trailers of a commit, empty if it was not made by gosh.
+//
+// Gosh-From-Version and Gosh-Version trailers belong to the Gosh-App trailer before them
+func ParseChangeEvents(commit *object.Commit) []ChangeEvent {
+	event := ChangeEvent{Commit: commit.Hash, Author: commit.Author}
+	var apps []ChangeEvent
+	for _, trailer := range ParseTrailers(commit.Message) {
+		switch trailer.Key {
+		case OperationTrailer:
+			operation, err := NewOperation(trailer.Value)
+			if err != nil {
+				log.Debugf("Ignoring unsupported gosh operation %s in commit %s", trailer.Value, commit.Hash)
+				return nil
+			}
+			event.Operation = operation
+		case TargetListTrailer:
+			event.TargetList = trailer.Value
+		case ResourceTrailer:
+			event.Resource = trailer.Value
+		case AppTrailer:
+			apps = append(apps, ChangeEvent{App: trailer.Value})
+		case FromVersionTrailer:
+			if len(apps) > 0 {
+				apps[len(apps)-1].FromVersion = trailer.Value
+			}
+		case VersionTrailer:
+			if len(apps) > 0 {
+				apps[len(apps)-1].Version = trailer.Value
+			}
+		case ForcedTrailer:
+			event.Forced = trailer.Value == "true"
+		case BuildUrlTrailer:
+			event.BuildUrl = trailer.Value
+		}
+	}
+	if event.Operation == 0 {
+		return nil
+	}
+	if len(apps) == 0 {
+		return []ChangeEvent{event}
+	}
+	events := make([]ChangeEvent, len(apps))
+	for i, app := range apps {
+		events[i] = event
+		events[i].App = app.App
+		events[i].FromVersion = app.FromVersion
+		events[i].Version = app.Version
+	}
+	return events
+}
+
+// ChangeEvents Returns the changes made by gosh in the commits reachable from 'to' but not from 'from', oldest first.
+//
+// Both are revisions like commit hashes, tags or branch names, 'to' defaults to HEAD and all commits are included when
+// 'from' is empty
+func (repo *DeploymentRepository) ChangeEvents(from string, to string) ([]ChangeEvent, error) {
+	commits, err := repo.commitRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var events []ChangeEvent
+	for _, commit := range commits {
+		events = append(events, ParseChangeEvents(commit)...)
+	}
+	return events, nil
+}
+
+// commitRange Returns the commits reachable from 'to' but not from 'from', oldest first
+func (repo *DeploymentRepository) commitRange(from string, to string) ([]*object.Commit, error) {
+	if !isValid(repo) || repo.git == nil {
+		return nil, errors.New("invalid DeploymentRepository struct, please use NewDeploymentRepository() to create one")
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	toHash, err := repo.git.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, log.Errf(err, "Could not resolve revision %s", to)
+	}
+	excluded := map[plumbing.Hash]bool{}
+	if from != "" {
+		fromHash, err := repo.git.ResolveRevision(plumbing.Revision(from))
+		if err != nil {
+			return nil, log.Errf(err, "Could not resolve revision %s", from)
+		}
+		if err = repo.eachCommit(*fromHash, func(commit *object.Commit) {
+			excluded[commit.Hash] = true
+		}); err != nil {
+			return nil, err
+		}
+	}
+	var commits []*object.Commit
+	err = repo.eachCommit(*toHash, func(commit *object.Commit) {
+		if !excluded[commit.Hash] {
+			//the log is newest first, prepend to return the oldest commit first
+			commits = append([]*object.Commit{commit}, commits...)
+		}
+	})
+	return commits, err
+}
+
+func (repo *DeploymentRepository) eachCommit(from plumbing.Hash, fn func(commit *object.Commit)) error {
+	commits, err := repo.git.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return log.Errf(err, "Could not read history from commit %s", from)
+	}
+	return commits.ForEach(func(commit *object.Commit) error {
+		fn(commit)
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type TrailersSuite struct {
+	suite.Suite
+}
+
+func (suite *TrailersSuite) TestParseTrailers() {
+	r := suite.Require()
+	r.Equal([]Trailer{{Key: "Gosh-Operation", Value: "create"}, {Key: "Signed-off-by", Value: "test <test@test>"}},
+		ParseTrailers("chore(gosh): create stage qa\n\nsome details\n\nGosh-Operation: create\nSigned-off-by: test <test@test>\n"))
+	r.Nil(ParseTrailers("Gosh-Operation: create"))
+	r.Nil(ParseTrailers("chore(gosh): create stage qa\n\nGosh-Operation: create\nnot a trailer"))
+}
+
+func (suite *TrailersSuite) TestNewOperation() {
+	r := suite.Require()
+	for o := CreateOperation; o <= ImportOperation; o++ {
+		operation, err := NewOperation(o.String())
+		r.Nil(err)
+		r.Equal(o, operation)
+	}
+	_, err := NewOperation("deploy")
+	r.Equal(UnsupportedOperationErr, err)
+	r.Equal("", Operation(0).String())
+	r.Equal("", (ImportOperation + 1).String())
+	r.NotPanics(func() { _ = fmt.Sprintf("%v", ChangeEvent{}) })
+}
+
+func (suite *TrailersSuite) TestParseChangeEvents() {
+	r := suite.Require()
+	msg, err := CommitMessage(CommitInfo{
+		Summary:   "promote 2 versions from stage dev to stage prod",
+		Operation: PromoteOperation,
+		Stage:     "prod",
+		Changes:   []AppVersionChange{{App: "app1", From: "1.0.0", To: "1.1.0"}, {App: "app2", To: "2.0.0"}},
+		Forced:    true,
+	})
+	r.Nil(err)
+	hash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	events := ParseChangeEvents(&object.Commit{Hash: hash, Author: object.Signature{Name: "test"}, Message: msg})
+	r.Len(events, 2)
+	r.Equal(ChangeEvent{Commit: hash, Author: object.Signature{Name: "test"}, Operation: PromoteOperation,
+		TargetList: "stage/prod", App: "app1", FromVersion: "1.0.0", Version: "1.1.0", Forced: true}, events[0])
+	r.Equal("app2", events[1].App)
+	r.Equal("", events[1].FromVersion)
+	r.Equal("2.0.0", events[1].Version)
+
+	msg, err = CommitMessage(CommitInfo{Summary: "create stage qa", Operation: CreateOperation, Resource: "stage/qa", Stage: "qa"})
+	r.Nil(err)
+	events = ParseChangeEvents(&object.Commit{Message: msg})
+	r.Len(events, 1)
+	r.Equal("stage/qa", events[0].Resource)
+	r.Equal("", events[0].App)
+
+	r.Empty(ParseChangeEvents(&object.Commit{Message: "manual change\n\nSigned-off-by: test <test@test>"}))
+	r.Empty(ParseChangeEvents(&object.Commit{Message: "manual change\n\nGosh-Operation: deploy"}))
+}
+
+func TestTrailersSuite(t *testing.T) {
+	suite.Run(t, new(TrailersSuite))
+}
@@ -70,6 +70,57 @@ func (app *App) Update() error {
 	return update(app)
 }
 
+// Delete Deletes the app and removes it from its group, fails with ResourceInUseErr when the app is still referenced
+func (app *App) Delete() error {
+	return app.delete(false)
+}
+
+// DeleteCascade Deletes the app like Delete, first removing its version from all stages and releases and its class from all targets
+func (app *App) DeleteCascade() error {
+	return app.delete(true)
+}
+
+func (app *App) delete(cascade bool) error {
+	if !app.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The app '%s' does not exist", app.Name)
+	}
+	refs, err := app.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to app %s", app.Name)
+	}
+	if !cascade {
+		if err = refs.inUseErr(app); err != nil {
+			return err
+		}
+	} else if err = refs.checkNotFrozen(); err != nil {
+		return err
+	}
+	return changeFiles(append(refs.files(), app.GetFilePath(), app.group.GetFilePath()), func() error {
+		if cascade {
+			if err := app.deleteReferences(refs); err != nil {
+				return err
+			}
+		}
+		if err := deleteResource(app); err != nil {
+			return err
+		}
+		return removeAppFromGroup(app)
+	})
+}
+
+func (app *App) deleteReferences(refs *references) error {
+	if err := refs.checkNotFrozen(); err != nil {
+		return err
+	}
+	if err := refs.removeVersions(app.Name); err != nil {
+		return err
+	}
+	if err := refs.removeVersionOverrides(app.Name); err != nil {
+		return err
+	}
+	return refs.removeTargetClasses()
+}
+
 func (app *App) Create() (err error) {
 	if err = prepareCreate(app); err == nil {
 		if err = createFromStruct(app); err == nil {
@@ -96,6 +147,21 @@ func addAppToGroup(app *App) error {
 	}
 }
 
+func removeAppFromGroup(app *App) error {
+	if err := app.group.Read(); err == nil {
+		apps := make([]*App, 0, len(app.group.Apps))
+		for _, a := range app.group.Apps {
+			if a.Name != app.Name {
+				apps = append(apps, a)
+			}
+		}
+		app.group.Apps = apps
+		return app.group.Update()
+	} else {
+		return log.Errf(err, "could not remove app %s from group", app.Name)
+	}
+}
+
 func prepareCreate(app *App) error {
 	log.Tracef("Create app with input: %+v", app)
 	if app == nil || !app.isValid() {
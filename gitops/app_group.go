@@ -74,7 +74,84 @@ func (group *AppGroup) Update() error {
 	return update(group)
 }
 
+// ListAppGroups Returns the sorted names of all app groups in the deployment repository
+func ListAppGroups() ([]string, error) {
+	return listResourceNames(filepath.Join(util.Context.WorkingDir, appGroupPath))
+}
+
+// Delete Deletes an empty app group, fails with ResourceInUseErr when it still has apps or is referenced by targets
 func (group *AppGroup) Delete() error {
+	return group.delete(false)
+}
+
+// DeleteCascade Deletes the app group and all its apps, removing all references to them, see App.DeleteCascade
+func (group *AppGroup) DeleteCascade() error {
+	return group.delete(true)
+}
+
+func (group *AppGroup) delete(cascade bool) error {
+	refs, err := group.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to app group %s", group.Name)
+	}
+	files := append(refs.files(), group.GetFilePath())
+	if !cascade {
+		if err = refs.inUseErr(group); err != nil {
+			return err
+		}
+	} else {
+		//check all apps first, so nothing is changed when one of them cannot be deleted
+		for _, app := range refs.apps {
+			r, err := app.findReferences()
+			if err != nil {
+				return log.Errf(err, "Could not find references to app %s", app.Name)
+			}
+			if err = r.checkNotFrozen(); err != nil {
+				return err
+			}
+			files = append(files, r.files()...)
+		}
+	}
+	err = changeFiles(files, func() error {
+		if cascade {
+			if err := group.deleteApps(refs.apps); err != nil {
+				return err
+			}
+			//the apps' references changed the targets, read them again before removing the group
+			targets, err := findTargetReferences(refs.classMatch)
+			if err != nil {
+				return err
+			}
+			refs.targets = targets
+			if err = refs.removeTargetClasses(); err != nil {
+				return err
+			}
+		}
+		return deleteResource(group)
+	})
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(group.GetFolderPath()); err != nil && !os.IsNotExist(err) {
+		return log.Errf(err, "Could not delete app group folder %s", group.GetFolderPath())
+	}
+	return nil
+}
+
+// deleteApps Deletes the apps of the group and all references to them
+func (group *AppGroup) deleteApps(apps []*App) error {
+	for _, app := range apps {
+		r, err := app.findReferences()
+		if err != nil {
+			return log.Errf(err, "Could not find references to app %s", app.Name)
+		}
+		if err = app.deleteReferences(r); err != nil {
+			return err
+		}
+		if err = deleteResource(app); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
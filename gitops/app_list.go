@@ -4,7 +4,7 @@ import (
 	"gosh/log"
 )
 
-//AppList An app list is a generic interface for Resource implementation that can give version information about a list of apps like Stage or Release
+//AppList An app list is a generic interface for Resource implementation that can give version information about a list of apps like Stage, Release or Target
 type AppList interface {
 	//getResourceName Returns the name of the Resource this AppList is linked to
 	getResourceName() string
@@ -24,6 +24,11 @@ type AppList interface {
 	//
 	//Used to provide generic GetVersions and GetArtifacts implementations
 	versions() map[string]string
+}
+
+//UpdatableAppList An AppList that holds the versions of its apps itself, so they can be updated, like Stage or Release
+type UpdatableAppList interface {
+	AppList
 	//UpdateVersion Updates the version for the app on the current Resource
 	UpdateVersion(app string, version string) error
 }
@@ -0,0 +1,298 @@
+package gitops
+
+import (
+	"errors"
+	"gosh/log"
+	"gosh/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	AppAlreadyInGroupErr = errors.New("app is already in this group")
+)
+
+// Move Moves the app to another group, which is created when it does not exist yet.
+//
+// The app file is moved as is, the app is removed from the class list of its old group and added to the new one
+// and all targets using the 'apps.GROUP.APP' class are updated. When one of the files cannot be written, all files are restored.
+func (app *App) Move(group *AppGroup) error {
+	if !app.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The app '%s' does not exist", app.Name)
+	}
+	if group == nil || !group.isValid() {
+		return log.Err(ValidationErr, "Invalid app group struct, use NewAppGroup() to create one")
+	}
+	if group.Name == app.group.Name {
+		return log.Errf(AppAlreadyInGroupErr, "The app '%s' is already in group '%s'", app.Name, group.Name)
+	}
+	refs, err := app.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to app %s", app.Name)
+	}
+	oldClass, oldGroup := app.className(), app.group
+	moved := NewApp(app.Name, group)
+	files := append(refs.files(), app.GetFilePath(), moved.GetFilePath(), app.group.GetFilePath(), group.GetFilePath())
+	err = changeFiles(files, func() error {
+		if !group.Exists() {
+			log.Debugf("Creating group %s for app %s", group.Name, app.Name)
+			if err := group.Create(); err != nil {
+				return log.Errf(err, "Error creating group %s for app %s", group.Name, app.Name)
+			}
+		}
+		if err := os.Rename(app.GetFilePath(), moved.GetFilePath()); err != nil {
+			return log.Errf(err, "Could not move app %s to group %s", app.Name, group.Name)
+		}
+		if err := removeAppFromGroup(app); err != nil {
+			return err
+		}
+		app.group = group
+		if err := addAppToGroup(app); err != nil {
+			return err
+		}
+		return refs.replaceTargetClasses(oldClass, app.className())
+	})
+	if err != nil {
+		app.group = oldGroup
+		return err
+	}
+	if targets, err := findTargetReferences(classMatcher(appPrefix + oldGroup.Name)); err == nil {
+		for _, target := range targets {
+			log.Warnf("Target %s uses group %s and no longer deploys app %s, add group %s to deploy it", target.Name, oldGroup.Name, app.Name, group.Name)
+		}
+	}
+	log.Infof("Moved app '%s' to group '%s'", app.Name, group.Name)
+	return nil
+}
+
+// Rename Renames the app, app names must be unique across groups.
+//
+// The parameters of the app file are moved to the new name, as is its 'app_name' property when it equals the old name,
+// other values in the app file are left untouched. The class list of the group, the versions of all stages and
+// releases, the classes of all targets, the parameters of the app in targets and classes like env classes and all
+// '${...}' references to the app are updated. Frozen releases cannot be changed, so an app that is part of a final
+// or end-of-life release cannot be renamed. When one of the files cannot be written, all files are restored.
+func (app *App) Rename(newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return log.Err(ValidationErr, "The new app name cannot be empty")
+	}
+	if !app.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The app '%s' does not exist", app.Name)
+	}
+	if existing, _ := FindApp(newName); existing != nil {
+		return log.Errf(ResourceAlreadyExistsErr, "The app '%s' already exists, app names must be unique", newName)
+	}
+	refs, err := app.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to app %s", app.Name)
+	}
+	if err = refs.checkNotFrozen(); err != nil {
+		return err
+	}
+	renamer, err := newAppRenamer(app.Name, newName)
+	if err != nil {
+		return err
+	}
+	files, err := listInventoryFiles()
+	if err != nil {
+		return err
+	}
+	oldName, oldClass := app.Name, app.className()
+	renamed := NewApp(newName, app.group)
+	err = changeFiles(append(files, renamed.GetFilePath()), func() error {
+		if err := renameAppFile(app, renamed); err != nil {
+			return err
+		}
+		if err := app.group.Read(); err != nil {
+			return log.Errf(err, "could not rename app %s in group", oldName)
+		}
+		for _, a := range app.group.Apps {
+			if a.Name == oldName {
+				a.Name = newName
+			}
+		}
+		if err := app.group.Update(); err != nil {
+			return log.Errf(err, "could not rename app %s in group", oldName)
+		}
+		app.Name = newName
+		if err := refs.renameVersions(oldName, newName); err != nil {
+			return err
+		}
+		if err := refs.replaceTargetClasses(oldClass, app.className()); err != nil {
+			return err
+		}
+		return renamer.renameInventory()
+	})
+	if err != nil {
+		app.Name = oldName
+		return err
+	}
+	log.Infof("Renamed app '%s' to '%s'", oldName, newName)
+	return nil
+}
+
+func (app *App) className() string {
+	return appPrefix + app.group.Name + "." + app.Name
+}
+
+func renameAppFile(app *App, renamed *App) error {
+	f, err := ReadKapitanFile(app.GetFilePath())
+	if err != nil {
+		return log.Errf(err, "Could not read app %s", app.Name)
+	}
+	if props, exists := f.Parameters[app.Name]; exists {
+		if m, ok := props.(map[interface{}]interface{}); ok && m["app_name"] == app.Name {
+			m["app_name"] = renamed.Name
+		}
+		delete(f.Parameters, app.Name)
+		f.Parameters[renamed.Name] = props
+	}
+	if err = WriteKapitanFile(renamed.GetFilePath(), f); err != nil {
+		return log.Errf(err, "Could not write app %s", renamed.Name)
+	}
+	if err = os.Remove(app.GetFilePath()); err != nil {
+		return log.Errf(err, "Could not remove app file %s", app.GetFilePath())
+	}
+	return nil
+}
+
+// appRenamer Rewrites the references to an app in kapitan files that are not managed by gosh resources: the parameters
+// of the app in targets and classes, e.g. version overrides, and '${...}' references to the parameters of the app or
+// to its version in a stage or release, like '${APP:version}' or '${STAGE:APP}'
+type appRenamer struct {
+	oldName  string
+	newName  string
+	appLists map[string]bool
+}
+
+func newAppRenamer(oldName string, newName string) (*appRenamer, error) {
+	r := &appRenamer{oldName: oldName, newName: newName, appLists: map[string]bool{}}
+	names, err := ListStages()
+	if err != nil {
+		return nil, err
+	}
+	for _, releaseType := range []ReleaseType{StageRelease, ProductRelease, HotFixRelease} {
+		releases, err := ListReleases(releaseType)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, releases...)
+	}
+	for _, name := range names {
+		r.appLists[name] = true
+	}
+	return r, nil
+}
+
+// renameInventory Rewrites all targets and classes referring to the app, files without references are left untouched
+func (r *appRenamer) renameInventory() error {
+	files, err := listInventoryFiles()
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		f, err := ReadKapitanFile(path)
+		if err != nil {
+			return log.Errf(err, "Could not read %s", path)
+		}
+		if r.renameFile(f) {
+			log.Debugf("Renaming app %s to %s in %s", r.oldName, r.newName, path)
+			if err = WriteKapitanFile(path, f); err != nil {
+				return log.Errf(err, "Could not rename app %s in %s", r.oldName, path)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *appRenamer) renameFile(f *kapitanFile) bool {
+	changed := false
+	if value, ok := f.Parameters[r.oldName].(map[interface{}]interface{}); ok {
+		delete(f.Parameters, r.oldName)
+		f.Parameters[r.newName] = value
+		changed = true
+	}
+	for key, value := range f.Parameters {
+		if renamed, ok := r.renameValue(value); ok {
+			f.Parameters[key] = renamed
+			changed = true
+		}
+	}
+	return changed
+}
+
+// renameValue Returns the value with all references to the app renamed, and whether anything was renamed
+func (r *appRenamer) renameValue(value interface{}) (interface{}, bool) {
+	changed := false
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for key, item := range v {
+			if renamed, ok := r.renameValue(item); ok {
+				v[key] = renamed
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if renamed, ok := r.renameValue(item); ok {
+				v[i] = renamed
+				changed = true
+			}
+		}
+	case string:
+		if renamed := r.renameReferences(v); renamed != v {
+			return renamed, true
+		}
+	}
+	return value, changed
+}
+
+func (r *appRenamer) renameReferences(value string) string {
+	if !strings.Contains(value, referenceOpenToken) {
+		return value
+	}
+	result := strings.Builder{}
+	last := 0
+	for _, match := range innermostReferenceRegexp.FindAllStringSubmatchIndex(value, -1) {
+		if match[0] > 0 && value[match[0]-1] == '\\' {
+			continue
+		}
+		parts := strings.Split(value[match[2]:match[3]], referenceSeparator)
+		if parts[0] == r.oldName {
+			parts[0] = r.newName
+		} else if len(parts) > 1 && r.appLists[parts[0]] && parts[1] == r.oldName {
+			parts[1] = r.newName
+		} else {
+			continue
+		}
+		result.WriteString(value[last:match[2]])
+		result.WriteString(strings.Join(parts, referenceSeparator))
+		last = match[3]
+	}
+	result.WriteString(value[last:])
+	return result.String()
+}
+
+// listInventoryFiles Returns all kapitan files of targets and classes
+func listInventoryFiles() ([]string, error) {
+	files := make([]string, 0)
+	for _, dir := range []string{targetsPath, kapitanClassesPath} {
+		root := filepath.Join(util.Context.WorkingDir, dir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && strings.HasSuffix(path, kapitanFileExt) {
+				files = append(files, path)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, log.Errf(err, "Could not list kapitan files in %s", root)
+		}
+	}
+	return files, nil
+}
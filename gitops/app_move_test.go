@@ -0,0 +1,148 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"path/filepath"
+	"testing"
+)
+
+type AppMoveSuite struct {
+	suite.Suite
+}
+
+func (suite *AppMoveSuite) SetupTest() {
+	TestsSetupWorkingDir(suite.Suite)
+	CreateTestAppGroup(suite.Suite, "test")
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	CreateTestStage(suite.Suite, "alpha")
+	CreateTestRelease(suite.Suite, "alpha", StageRelease)
+	CreateTestRelease(suite.Suite, "my-release", ProductRelease)
+	CreateTestTarget(suite.Suite, "my-target")
+	target := NewTarget("my-target")
+	suite.Require().Nil(target.Read())
+	target.AddClass("apps.test.app1")
+	suite.Require().Nil(target.Update())
+}
+
+func (suite *AppMoveSuite) TearDownTest() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *AppMoveSuite) TestMove() {
+	r := suite.Require()
+	app, err := FindApp("app1")
+	r.Nil(err)
+	r.Nil(app.Move(NewAppGroup("other")))
+
+	app, err = FindApp("app1")
+	r.Nil(err)
+	r.Nil(app.Read())
+	r.Equal("app1", app.Properties["app_name"])
+	group := NewAppGroup("other")
+	r.Nil(group.Read())
+	r.Len(group.Apps, 1)
+	r.Equal("app1", group.Apps[0].Name)
+	group = NewAppGroup("test")
+	r.Nil(group.Read())
+	r.Len(group.Apps, 1)
+	r.Equal("app2", group.Apps[0].Name)
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.Equal([]string{"releases.product.my-release", "stages.alpha", "apps.test", "apps.other.app1"}, target.Classes)
+}
+
+func (suite *AppMoveSuite) TestMoveToSameGroupReturnsErr() {
+	app, _ := FindApp("app1")
+	suite.Require().Equal(AppAlreadyInGroupErr, app.Move(NewAppGroup("test")))
+}
+
+func (suite *AppMoveSuite) TestRename() {
+	r := suite.Require()
+	app, _ := FindApp("app1")
+	r.Nil(app.Rename("renamed"))
+
+	_, err := FindApp("app1")
+	r.Equal(ResourceDoesNotExistErr, err)
+	app, err = FindApp("renamed")
+	r.Nil(err)
+	r.Nil(app.Read())
+	r.Equal("renamed", app.Properties["app_name"])
+	group := NewAppGroup("test")
+	r.Nil(group.Read())
+	r.Equal("renamed", group.Apps[0].Name)
+	r.Equal("app2", group.Apps[1].Name)
+	stage := NewStage("alpha")
+	r.Nil(stage.Read())
+	r.Equal(map[string]string{"renamed": "1.0.0", "app2": "2.0.0", "app3": "3.0.0"}, stage.Versions)
+	release := NewRelease("my-release", ProductRelease)
+	r.Nil(release.Read())
+	r.Equal("1.0.0", release.Versions["renamed"])
+	r.NotContains(release.Versions, "app1")
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.True(target.HasClass("apps.test.renamed"))
+	r.False(target.HasClass("apps.test.app1"))
+	r.Equal(map[interface{}]interface{}{"version": "1.1.0"}, target.Parameters["renamed"])
+	r.NotContains(target.Parameters, "app1")
+}
+
+func (suite *AppMoveSuite) TestRenameOverridesAndReferences() {
+	r := suite.Require()
+	CreateTestEnvClass(suite.Suite, "dev")
+	env := NewEnvClass("dev")
+	r.Nil(env.Read())
+	env.Parameters["app1"] = map[interface{}]interface{}{"version": "1.0.1"}
+	env.Parameters["image"] = "registry/app1:${app1:version}"
+	env.Parameters["deployed"] = []interface{}{"${alpha:app1}", "${app2:version}", `\${app1:version}`}
+	r.Nil(env.Update())
+	app, _ := FindApp("app1")
+	r.Nil(app.Rename("renamed"))
+
+	env = NewEnvClass("dev")
+	r.Nil(env.Read())
+	r.Equal(map[interface{}]interface{}{"version": "1.0.1"}, env.Parameters["renamed"])
+	r.NotContains(env.Parameters, "app1")
+	r.Equal("registry/app1:${renamed:version}", env.Parameters["image"])
+	r.Equal([]interface{}{"${alpha:renamed}", "${app2:version}", `\${app1:version}`}, env.Parameters["deployed"])
+}
+
+func (suite *AppMoveSuite) TestRenameRestoresFilesOnErr() {
+	r := suite.Require()
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/classes/broken.yml"), "parameters: [")
+	app, _ := FindApp("app1")
+	r.NotNil(app.Rename("renamed"))
+
+	r.Equal("app1", app.Name)
+	_, err := FindApp("renamed")
+	r.Equal(ResourceDoesNotExistErr, err)
+	app, err = FindApp("app1")
+	r.Nil(err)
+	r.Nil(app.Read())
+	r.Equal("app1", app.Properties["app_name"])
+	stage := NewStage("alpha")
+	r.Nil(stage.Read())
+	r.Contains(stage.Versions, "app1")
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.True(target.HasClass("apps.test.app1"))
+}
+
+func (suite *AppMoveSuite) TestRenameExistingReturnsErr() {
+	app, _ := FindApp("app1")
+	suite.Require().Equal(ResourceAlreadyExistsErr, app.Rename("app2"))
+}
+
+func (suite *AppMoveSuite) TestRenameFrozenReleaseReturnsErr() {
+	r := suite.Require()
+	r.Nil(NewRelease("my-release", ProductRelease).Finalize())
+	app, _ := FindApp("app1")
+	r.Equal(ReleaseFrozenErr, app.Rename("renamed"))
+	r.True(app.Exists())
+}
+
+func TestAppMoveTestSuite(t *testing.T) {
+	suite.Run(t, new(AppMoveSuite))
+}
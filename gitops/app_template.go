@@ -12,6 +12,7 @@ import (
 )
 
 const (
+	appTemplatesPath           = ".gosh/templates"
 	defaultAppTemplateContents = `parameters:
   {{.Name}}:
     app_name: {{.Name}}
@@ -55,7 +56,7 @@ func NewAppTemplate(templateName string) (*AppTemplate, error) {
 	if templateName == "" {
 		return DefaultAppTemplate, nil
 	}
-	templateFile := filepath.Join(util.Context.WorkingDir, ".gosh", "templates", templateName+".yml")
+	templateFile := filepath.Join(util.Context.WorkingDir, appTemplatesPath, templateName+kapitanFileExt)
 	if info, err := os.Stat(templateFile); err == nil && !info.IsDir() {
 		if data, err := ioutil.ReadFile(templateFile); err == nil {
 			if t, err := template.New("default").Parse(string(data)); err == nil {
@@ -0,0 +1,122 @@
+package gitops
+
+import (
+	"gosh/log"
+	"gosh/util"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	envClassesPath = kapitanClassesPath + "env"
+	envClassPrefix = "env."
+)
+
+// EnvClass An environment class is a blueprint for common types of targets, e.g. dev, production...
+type EnvClass struct {
+	Name       string
+	Classes    []string
+	Parameters map[interface{}]interface{}
+	_read      bool
+}
+
+func (env *EnvClass) initialized() bool {
+	return env._read
+}
+
+func (env *EnvClass) setInitialized() {
+	env._read = true
+}
+
+func NewEnvClass(name string) *EnvClass {
+	return &EnvClass{Name: strings.ToLower(name), Classes: []string{}, Parameters: map[interface{}]interface{}{}}
+}
+
+// ListEnvClasses Returns the sorted names of all environment classes in the deployment repository
+func ListEnvClasses() ([]string, error) {
+	return listResourceNames(filepath.Join(util.Context.WorkingDir, envClassesPath))
+}
+
+func (env *EnvClass) Create() error {
+	return create(env)
+}
+
+func (env *EnvClass) Read() error {
+	return read(env)
+}
+
+func (env *EnvClass) Update() error {
+	return update(env)
+}
+
+// Delete Deletes the environment class, fails with ResourceInUseErr when targets still use it
+func (env *EnvClass) Delete() error {
+	if !env.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The env class '%s' does not exist", env.Name)
+	}
+	refs, err := env.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to environment class %s", env.Name)
+	}
+	if err = refs.inUseErr(env); err != nil {
+		return err
+	}
+	return deleteResource(env)
+}
+
+// GetParameter Returns the value of a parameter, nested parameters can be accessed using a dotted key like 'feature.enabled'
+func (env *EnvClass) GetParameter(key string) (interface{}, bool) {
+	return getParameter(env.Parameters, key)
+}
+
+// SetParameter Sets the value of a parameter, nested parameters can be set using a dotted key like 'feature.enabled'
+func (env *EnvClass) SetParameter(key string, value interface{}) {
+	setParameter(env.Parameters, key, value)
+}
+
+// UnsetParameter Removes a parameter, returns false if the parameter was not set
+func (env *EnvClass) UnsetParameter(key string) bool {
+	return unsetParameter(env.Parameters, key)
+}
+
+func (env *EnvClass) mapToKapitanFile() *kapitanFile {
+	log.Tracef("Mapping env class %s to kapitan file: %+v", env.Name, env)
+	f := newKapitanFile()
+	f.Classes = append(f.Classes, env.Classes...)
+	for key, value := range env.Parameters {
+		f.Parameters[key] = value
+	}
+	log.Tracef("Mapped env class %s to kapitan file, result: %+v", env.Name, f)
+	return f
+}
+
+func (env *EnvClass) mapFromKapitanFile(f *kapitanFile) {
+	log.Tracef("Mapping env class %s from kapitan file %+v", env.Name, f)
+	env.Classes = make([]string, 0)
+	env.Classes = append(env.Classes, f.Classes...)
+	env.Parameters = make(map[interface{}]interface{}, 0)
+	for key, value := range f.Parameters {
+		env.Parameters[key] = value
+	}
+	log.Tracef("Mapped env class %s from kapitan file, result: %+v", env.Name, env)
+}
+
+func (env *EnvClass) Exists() bool {
+	return exists(env)
+}
+
+func (env *EnvClass) GetFilePath() string {
+	return filepath.Join(util.Context.WorkingDir, envClassesPath, env.Name+kapitanFileExt)
+}
+
+func (env *EnvClass) isValid() bool {
+	return strings.TrimSpace(env.Name) != ""
+}
+
+func (env *EnvClass) getResourceType() string {
+	return "env class"
+}
+
+func (env *EnvClass) getResourceName() string {
+	return env.Name
+}
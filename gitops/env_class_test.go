@@ -0,0 +1,95 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"path/filepath"
+	"testing"
+)
+
+type EnvClassSuite struct {
+	suite.Suite
+}
+
+func (suite *EnvClassSuite) SetupSuite() {
+	TestsSetupWorkingDir(suite.Suite)
+	CreateTestEnvClass(suite.Suite, "dev")
+}
+
+func (suite *EnvClassSuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *EnvClassSuite) TestGetFilePath() {
+	env := NewEnvClass("Production")
+	r := suite.Require()
+	r.Equal(filepath.Join(util.Context.WorkingDir, "inventory/classes/env/production.yml"), env.GetFilePath())
+}
+
+func (suite *EnvClassSuite) TestReadInvalidStructReturnValidationErr() {
+	env := &EnvClass{}
+	err := env.Read()
+	r := suite.Require()
+	r.NotNil(err)
+	r.Equal(ValidationErr, err)
+}
+
+func (suite *EnvClassSuite) TestRead() {
+	env := NewEnvClass("dev")
+	err := env.Read()
+	r := suite.Require()
+	r.Nil(err)
+	value, exists := env.GetParameter("features.debug")
+	r.True(exists)
+	r.Equal(true, value)
+	value, exists = env.GetParameter("replicas")
+	r.True(exists)
+	r.Equal(1, value)
+	_, exists = env.GetParameter("features.unknown")
+	r.False(exists)
+	_, exists = env.GetParameter("replicas.nested")
+	r.False(exists)
+}
+
+func (suite *EnvClassSuite) TestCreate() {
+	env := NewEnvClass("production")
+	env.SetParameter("features.debug", false)
+	err := env.Create()
+	r := suite.Require()
+	r.Nil(err)
+
+	env = NewEnvClass("production")
+	err = env.Read()
+	r.Nil(err)
+	value, exists := env.GetParameter("features.debug")
+	r.True(exists)
+	r.Equal(false, value)
+}
+
+func (suite *EnvClassSuite) TestUpdate() {
+	env := NewEnvClass("dev")
+	r := suite.Require()
+	err := env.Read()
+	r.Nil(err)
+	env.SetParameter("features.tracing", "enabled")
+	env.SetParameter("replicas", 2)
+	r.True(env.UnsetParameter("features.debug"))
+	r.False(env.UnsetParameter("features.unknown"))
+	err = env.Update()
+	r.Nil(err)
+
+	env = NewEnvClass("dev")
+	err = env.Read()
+	r.Nil(err)
+	value, _ := env.GetParameter("features.tracing")
+	r.Equal("enabled", value)
+	value, _ = env.GetParameter("replicas")
+	r.Equal(2, value)
+	_, exists := env.GetParameter("features.debug")
+	r.False(exists)
+}
+
+func TestEnvClassTestSuite(t *testing.T) {
+	suite.Run(t, new(EnvClassSuite))
+}
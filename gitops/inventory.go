@@ -0,0 +1,306 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"gosh/log"
+	"gosh/util"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	kapitanInitFile    = "init" + kapitanFileExt
+	overrideKeyPrefix  = "~"
+	referenceSeparator = ":"
+	escapedReference   = `\${`
+	escapedPlaceholder = "\x00"
+	referenceOpenToken = "${"
+)
+
+var (
+	ClassNotFoundErr                 = errors.New("class not found")
+	CircularClassReferenceErr        = errors.New("circular class reference")
+	CircularParameterReferenceErr    = errors.New("circular parameter reference")
+	UnresolvedParameterReferenceErr  = errors.New("unresolved parameter reference")
+	InvalidParameterInterpolationErr = errors.New("only scalar values can be interpolated in a string")
+	innermostReferenceRegexp         = regexp.MustCompile(`\$\{([^${}]+)\}`)
+	singleReferenceRegexp            = regexp.MustCompile(`^\$\{([^${}]+)\}$`)
+)
+
+// InventoryTarget The fully resolved inventory of a target, as kapitan would compute it.
+//
+// Classes contains all classes in the order they were applied, Parameters the merged and interpolated parameters tree.
+type InventoryTarget struct {
+	Name            string
+	Classes         []string
+	Parameters      map[interface{}]interface{}
+	classParameters map[string]map[interface{}]interface{}
+}
+
+// GetParameter Returns the value of a resolved parameter, nested parameters can be accessed using a dotted key like 'kapitan.vars.target'
+func (inventory *InventoryTarget) GetParameter(key string) (interface{}, bool) {
+	return getParameter(inventory.Parameters, key)
+}
+
+// interpolate Resolves the '${key:subkey}' references in a raw parameter value against the resolved parameters
+func (inventory *InventoryTarget) interpolate(value interface{}) (interface{}, error) {
+	return newParameterInterpolator(inventory.Parameters).resolveValue(value)
+}
+
+type inventoryResolver struct {
+	classes         []string
+	applied         map[string]bool
+	visiting        map[string]bool
+	parameters      map[interface{}]interface{}
+	classParameters map[string]map[interface{}]interface{}
+}
+
+// ResolveInventory Resolves the classes and parameters of a target the way reclass/kapitan does.
+//
+// Dotted class names are mapped to 'inventory/classes/a/b.yml' or 'inventory/classes/a/b/init.yml', class names starting
+// with a dot are relative to the directory of the class that includes them. Classes are applied depth-first and only once,
+// parameters of later classes are deep-merged over earlier ones: maps are merged, lists are extended and other values
+// are replaced. Keys prefixed with '~' replace the value instead of merging it. Finally '${key:subkey}' references
+// are interpolated, use '\${' to escape a reference.
+func ResolveInventory(target *Target) (*InventoryTarget, error) {
+	if err := target.Read(); err != nil {
+		return nil, err
+	}
+	r := &inventoryResolver{
+		classes:         []string{},
+		applied:         map[string]bool{},
+		visiting:        map[string]bool{},
+		parameters:      map[interface{}]interface{}{},
+		classParameters: map[string]map[interface{}]interface{}{},
+	}
+	for _, class := range target.Classes {
+		if err := r.loadClass(class, ""); err != nil {
+			return nil, log.Errf(err, "Could not resolve classes of target %s", target.Name)
+		}
+	}
+	mergeParameters(r.parameters, target.Parameters)
+	parameters, err := newParameterInterpolator(r.parameters).interpolate()
+	if err != nil {
+		return nil, log.Errf(err, "Could not interpolate parameters of target %s", target.Name)
+	}
+	log.Debugf("Resolved inventory for target %s with classes %v", target.Name, r.classes)
+	return &InventoryTarget{Name: target.Name, Classes: r.classes, Parameters: parameters, classParameters: r.classParameters}, nil
+}
+
+// resolveClassName Resolves relative class names like '.sibling' or '..parent' against the package of the including class
+func resolveClassName(class string, parentPackage string) string {
+	if !strings.HasPrefix(class, ".") {
+		return class
+	}
+	name := strings.TrimLeft(class, ".")
+	levels := len(class) - len(name) - 1
+	parts := make([]string, 0)
+	if parentPackage != "" {
+		parts = strings.Split(parentPackage, ".")
+	}
+	if levels > len(parts) {
+		levels = len(parts)
+	}
+	parts = append(parts[:len(parts)-levels], name)
+	return strings.Join(parts, ".")
+}
+
+// findClassFile Returns the file defining the class, and the package relative classes in that file resolve against
+func findClassFile(class string) (string, string, error) {
+	if f := classFilePath(class); fileExists(f) {
+		pkg := ""
+		if i := strings.LastIndex(class, "."); i >= 0 {
+			pkg = class[:i]
+		}
+		return f, pkg, nil
+	}
+	f := filepath.Join(util.Context.WorkingDir, kapitanClassesPath, filepath.FromSlash(strings.ReplaceAll(class, ".", "/")), kapitanInitFile)
+	if info, err := os.Stat(f); err == nil && !info.IsDir() {
+		return f, class, nil
+	}
+	return "", "", ClassNotFoundErr
+}
+
+func (r *inventoryResolver) loadClass(class string, parentPackage string) error {
+	class = resolveClassName(class, parentPackage)
+	if r.applied[class] {
+		log.Tracef("Class %s already applied, skipping", class)
+		return nil
+	}
+	if r.visiting[class] {
+		return log.Errf(CircularClassReferenceErr, "Class %s references itself", class)
+	}
+	r.visiting[class] = true
+	defer delete(r.visiting, class)
+	path, pkg, err := findClassFile(class)
+	if err != nil {
+		return log.Errf(err, "Class %s not found", class)
+	}
+	f, err := ReadKapitanFile(path)
+	if err != nil {
+		return log.Errf(err, "Could not read class %s", class)
+	}
+	for _, c := range f.Classes {
+		if err = r.loadClass(c, pkg); err != nil {
+			return err
+		}
+	}
+	mergeParameters(r.parameters, f.Parameters)
+	r.classParameters[class] = f.Parameters
+	r.applied[class] = true
+	r.classes = append(r.classes, class)
+	return nil
+}
+
+// mergeParameters Deep-merges src into dst using reclass merge semantics
+func mergeParameters(dst map[interface{}]interface{}, src map[interface{}]interface{}) {
+	for key, value := range src {
+		if k, ok := key.(string); ok && strings.HasPrefix(k, overrideKeyPrefix) {
+			dst[strings.TrimPrefix(k, overrideKeyPrefix)] = copyParameter(value)
+			continue
+		}
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			if existing, ok := dst[key].(map[interface{}]interface{}); ok {
+				mergeParameters(existing, v)
+			} else {
+				merged := map[interface{}]interface{}{}
+				mergeParameters(merged, v)
+				dst[key] = merged
+			}
+		case []interface{}:
+			if existing, ok := dst[key].([]interface{}); ok {
+				dst[key] = append(existing, copyParameter(v).([]interface{})...)
+			} else {
+				dst[key] = copyParameter(v)
+			}
+		default:
+			dst[key] = v
+		}
+	}
+}
+
+func copyParameter(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[interface{}]interface{}{}
+		mergeParameters(result, v)
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			result = append(result, copyParameter(item))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+type parameterInterpolator struct {
+	parameters map[interface{}]interface{}
+	resolved   map[string]interface{}
+	visiting   map[string]bool
+}
+
+func newParameterInterpolator(parameters map[interface{}]interface{}) *parameterInterpolator {
+	return &parameterInterpolator{
+		parameters: parameters,
+		resolved:   map[string]interface{}{},
+		visiting:   map[string]bool{},
+	}
+}
+
+func (i *parameterInterpolator) interpolate() (map[interface{}]interface{}, error) {
+	result, err := i.resolveValue(i.parameters)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[interface{}]interface{}), nil
+}
+
+func (i *parameterInterpolator) resolveValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[interface{}]interface{}{}
+		for key, item := range v {
+			resolved, err := i.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			resolved, err := i.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, resolved)
+		}
+		return result, nil
+	case string:
+		return i.resolveString(v)
+	default:
+		return v, nil
+	}
+}
+
+func (i *parameterInterpolator) resolveString(value string) (interface{}, error) {
+	if !strings.Contains(value, referenceOpenToken) {
+		return value, nil
+	}
+	s := strings.ReplaceAll(value, escapedReference, escapedPlaceholder)
+	for {
+		if match := singleReferenceRegexp.FindStringSubmatch(s); match != nil {
+			return i.lookup(match[1])
+		}
+		match := innermostReferenceRegexp.FindStringSubmatchIndex(s)
+		if match == nil {
+			break
+		}
+		ref := s[match[2]:match[3]]
+		resolved, err := i.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		switch resolved.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, log.Errf(InvalidParameterInterpolationErr, "Reference ${%s} in '%s' does not resolve to a scalar value", ref, value)
+		}
+		s = s[:match[0]] + fmt.Sprint(resolved) + s[match[1]:]
+	}
+	return strings.ReplaceAll(s, escapedPlaceholder, referenceOpenToken), nil
+}
+
+func (i *parameterInterpolator) lookup(ref string) (interface{}, error) {
+	if value, exists := i.resolved[ref]; exists {
+		return copyParameter(value), nil
+	}
+	if i.visiting[ref] {
+		return nil, log.Errf(CircularParameterReferenceErr, "Parameter reference ${%s} references itself", ref)
+	}
+	i.visiting[ref] = true
+	defer delete(i.visiting, ref)
+	var current interface{} = i.parameters
+	for _, part := range strings.Split(ref, referenceSeparator) {
+		m, ok := current.(map[interface{}]interface{})
+		if !ok {
+			return nil, log.Errf(UnresolvedParameterReferenceErr, "Parameter reference ${%s} cannot be resolved", ref)
+		}
+		if current, ok = m[part]; !ok {
+			return nil, log.Errf(UnresolvedParameterReferenceErr, "Parameter reference ${%s} cannot be resolved", ref)
+		}
+	}
+	resolved, err := i.resolveValue(current)
+	if err != nil {
+		return nil, err
+	}
+	i.resolved[ref] = resolved
+	return copyParameter(resolved), nil
+}
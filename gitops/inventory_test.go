@@ -0,0 +1,184 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type InventorySuite struct {
+	suite.Suite
+}
+
+func (suite *InventorySuite) SetupSuite() {
+	TestsSetupWorkingDir(suite.Suite)
+	suite.createFile("inventory/classes/common.yml", `
+parameters:
+  namespace: default
+  labels:
+    team: platform
+  features:
+    - metrics
+`)
+	suite.createFile("inventory/classes/components/web/init.yml", `
+classes:
+  - common
+  - .ports
+parameters:
+  web:
+    image: nginx:${web:version}
+    version: "1.21"
+    namespace: ${namespace}
+    labels: ${labels}
+`)
+	suite.createFile("inventory/classes/components/web/ports.yml", `
+parameters:
+  web:
+    port: 80
+`)
+	suite.createFile("inventory/classes/env/dev.yml", `
+classes:
+  - common
+parameters:
+  namespace: dev
+  labels:
+    env: dev
+  features:
+    - tracing
+  ~replaced:
+    only: this
+  escaped: \${not_a_reference}
+`)
+	suite.createFile("inventory/classes/base.yml", `
+parameters:
+  replaced:
+    removed: value
+`)
+	suite.createFile("inventory/targets/web-dev.yml", `
+classes:
+  - base
+  - components.web
+  - env.dev
+parameters:
+  kapitan:
+    vars:
+      target: web-dev
+  web:
+    version: "1.22"
+`)
+	suite.createFile("inventory/classes/loop/a.yml", `
+classes:
+  - .b
+`)
+	suite.createFile("inventory/classes/loop/b.yml", `
+classes:
+  - loop.a
+`)
+}
+
+func (suite *InventorySuite) createFile(path string, contents string) {
+	f := filepath.Join(util.Context.WorkingDir, path)
+	_ = os.MkdirAll(filepath.Dir(f), 0755)
+	filet.File(suite.T(), f, contents)
+}
+
+func (suite *InventorySuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *InventorySuite) TestResolveClassName() {
+	r := suite.Require()
+	r.Equal("a.b", resolveClassName("a.b", "c"))
+	r.Equal("c.d.b", resolveClassName(".b", "c.d"))
+	r.Equal("c.b", resolveClassName("..b", "c.d"))
+	r.Equal("b", resolveClassName(".b", ""))
+}
+
+func (suite *InventorySuite) TestResolveInventory() {
+	inventory, err := ResolveInventory(NewTarget("web-dev"))
+	r := suite.Require()
+	r.Nil(err)
+	r.Equal([]string{"base", "common", "components.web.ports", "components.web", "env.dev"}, inventory.Classes)
+
+	value, _ := inventory.GetParameter("namespace")
+	r.Equal("dev", value)
+	value, _ = inventory.GetParameter("web.namespace")
+	r.Equal("dev", value)
+	value, _ = inventory.GetParameter("web.image")
+	r.Equal("nginx:1.22", value)
+	value, _ = inventory.GetParameter("web.port")
+	r.Equal(80, value)
+	value, _ = inventory.GetParameter("web.labels")
+	r.Equal(map[interface{}]interface{}{"team": "platform", "env": "dev"}, value)
+	value, _ = inventory.GetParameter("features")
+	r.Equal([]interface{}{"metrics", "tracing"}, value)
+	value, _ = inventory.GetParameter("replaced")
+	r.Equal(map[interface{}]interface{}{"only": "this"}, value)
+	value, _ = inventory.GetParameter("escaped")
+	r.Equal("${not_a_reference}", value)
+	value, _ = inventory.GetParameter("kapitan.vars.target")
+	r.Equal("web-dev", value)
+}
+
+func (suite *InventorySuite) TestResolveInventoryMissingClass() {
+	suite.createFile("inventory/targets/missing.yml", `
+classes:
+  - does.not.exist
+`)
+	_, err := ResolveInventory(NewTarget("missing"))
+	r := suite.Require()
+	r.Equal(ClassNotFoundErr, err)
+}
+
+func (suite *InventorySuite) TestResolveInventoryCircularClasses() {
+	suite.createFile("inventory/targets/loop.yml", `
+classes:
+  - loop.a
+`)
+	_, err := ResolveInventory(NewTarget("loop"))
+	r := suite.Require()
+	r.Equal(CircularClassReferenceErr, err)
+}
+
+func (suite *InventorySuite) TestResolveInventoryUnresolvedReference() {
+	suite.createFile("inventory/targets/unresolved.yml", `
+parameters:
+  value: ${does:not:exist}
+`)
+	_, err := ResolveInventory(NewTarget("unresolved"))
+	r := suite.Require()
+	r.Equal(UnresolvedParameterReferenceErr, err)
+}
+
+func (suite *InventorySuite) TestResolveInventoryCircularReference() {
+	suite.createFile("inventory/targets/circular.yml", `
+parameters:
+  a: ${b}
+  b: prefix-${a}
+`)
+	_, err := ResolveInventory(NewTarget("circular"))
+	r := suite.Require()
+	r.Equal(CircularParameterReferenceErr, err)
+}
+
+func (suite *InventorySuite) TestResolveInventoryNestedReference() {
+	suite.createFile("inventory/targets/nested.yml", `
+parameters:
+  env: prod
+  urls:
+    prod: https://prod.example.com
+  url: ${urls:${env}}/api
+`)
+	inventory, err := ResolveInventory(NewTarget("nested"))
+	r := suite.Require()
+	r.Nil(err)
+	value, _ := inventory.GetParameter("url")
+	r.Equal("https://prod.example.com/api", value)
+}
+
+func TestInventoryTestSuite(t *testing.T) {
+	suite.Run(t, new(InventorySuite))
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"gopkg.in/yaml.v2"
 	"gosh/log"
+	"gosh/util"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -45,8 +47,7 @@ func ReadKapitanFile(filePath string) (*kapitanFile, error) {
 	}
 	log.Debug("Reading kapitan resource", filePath)
 	if data, err := ioutil.ReadFile(filePath); err == nil {
-		var file = &kapitanFile{}
-		if err = yaml.Unmarshal(data, file); err == nil {
+		if file, err := parseKapitanFile(data); err == nil {
 			log.Trace("Read data", file)
 			return file, nil
 		} else {
@@ -57,6 +58,14 @@ func ReadKapitanFile(filePath string) (*kapitanFile, error) {
 	}
 }
 
+func parseKapitanFile(data []byte) (*kapitanFile, error) {
+	var file = &kapitanFile{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
 func WriteKapitanFile(filePath string, data *kapitanFile) error {
 	if data == nil {
 		return missingArgumentErr
@@ -68,3 +77,58 @@ func WriteKapitanFile(filePath string, data *kapitanFile) error {
 		return log.CheckErr(err, "error converting data to YAML", data)
 	}
 }
+
+// getParameter Returns the value of a parameter using a dotted key, e.g. 'app.artifacts.maven'
+func getParameter(parameters map[interface{}]interface{}, key string) (interface{}, bool) {
+	parts := strings.Split(key, ".")
+	var current interface{} = parameters
+	for _, part := range parts {
+		if m, ok := current.(map[interface{}]interface{}); ok {
+			if current, ok = m[part]; !ok {
+				return nil, false
+			}
+		} else {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setParameter Sets the value of a parameter using a dotted key, intermediate maps are created when needed
+func setParameter(parameters map[interface{}]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	current := parameters
+	for _, part := range parts[:len(parts)-1] {
+		if next, ok := current[part].(map[interface{}]interface{}); ok {
+			current = next
+		} else {
+			next = map[interface{}]interface{}{}
+			current[part] = next
+			current = next
+		}
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+// unsetParameter Removes a parameter using a dotted key, returns false if the parameter did not exist
+func unsetParameter(parameters map[interface{}]interface{}, key string) bool {
+	parts := strings.Split(key, ".")
+	current := parameters
+	for _, part := range parts[:len(parts)-1] {
+		if next, ok := current[part].(map[interface{}]interface{}); ok {
+			current = next
+		} else {
+			return false
+		}
+	}
+	if _, exists := current[parts[len(parts)-1]]; exists {
+		delete(current, parts[len(parts)-1])
+		return true
+	}
+	return false
+}
+
+// classFilePath Returns the file path of a dotted kapitan class name, e.g. 'stages.alpha' resolves to 'inventory/classes/stages/alpha.yml'
+func classFilePath(class string) string {
+	return filepath.Join(util.Context.WorkingDir, kapitanClassesPath, filepath.FromSlash(strings.ReplaceAll(class, ".", "/"))+kapitanFileExt)
+}
@@ -0,0 +1,374 @@
+package gitops
+
+import (
+	"gosh/log"
+	"strings"
+)
+
+// references The resources referring to a resource that is about to be deleted.
+//
+// Stages and releases refer to an app through their versions, targets through the classes matched by classMatch.
+// Targets and env classes also refer to an app through version overrides of the form 'APP: {version: VERSION}'.
+type references struct {
+	apps            []*App
+	stages          []*Stage
+	releases        []*Release
+	targets         []*Target
+	targetOverrides []*Target
+	envOverrides    []*EnvClass
+	classMatch      func(class string) bool
+}
+
+// list Returns the type and name of all referencing resources, e.g. "stage 'alpha'"
+func (refs *references) list() []string {
+	result := make([]string, 0)
+	add := func(resourceType string, name string) {
+		ref := resourceType + " '" + name + "'"
+		for _, r := range result {
+			if r == ref {
+				return
+			}
+		}
+		result = append(result, ref)
+	}
+	for _, app := range refs.apps {
+		add(app.getResourceType(), app.Name)
+	}
+	for _, stage := range refs.stages {
+		add(stage.getResourceType(), stage.Name)
+	}
+	for _, release := range refs.releases {
+		add(release.getResourceType(), release.FullName())
+	}
+	for _, target := range refs.targets {
+		add(target.getResourceType(), target.Name)
+	}
+	for _, target := range refs.targetOverrides {
+		add(target.getResourceType(), target.Name)
+	}
+	for _, env := range refs.envOverrides {
+		add(env.getResourceType(), env.Name)
+	}
+	return result
+}
+
+// files Returns the files of all referencing resources, which are changed when the references are removed or renamed
+func (refs *references) files() []string {
+	files := make([]string, 0)
+	for _, app := range refs.apps {
+		files = append(files, app.GetFilePath())
+	}
+	for _, stage := range refs.stages {
+		files = append(files, stage.GetFilePath())
+	}
+	for _, release := range refs.releases {
+		files = append(files, release.GetFilePath())
+	}
+	for _, target := range refs.targets {
+		files = append(files, target.GetFilePath())
+	}
+	for _, target := range refs.targetOverrides {
+		files = append(files, target.GetFilePath())
+	}
+	for _, env := range refs.envOverrides {
+		files = append(files, env.GetFilePath())
+	}
+	return files
+}
+
+// inUseErr Returns ResourceInUseErr listing the references, or nil if there are none
+func (refs *references) inUseErr(resource Resource) error {
+	if list := refs.list(); len(list) > 0 {
+		return log.Errf(ResourceInUseErr, "The %s '%s' is still referenced by %s, use cascade to remove the references",
+			resource.getResourceType(), resource.getResourceName(), strings.Join(list, ", "))
+	}
+	return nil
+}
+
+// checkNotFrozen Returns ReleaseFrozenErr if one of the referencing releases can no longer be changed
+func (refs *references) checkNotFrozen() error {
+	for _, release := range refs.releases {
+		if release.State.IsFrozen() {
+			return log.Errf(ReleaseFrozenErr, "Release %s is %s, versions can no longer be removed", release.FullName(), release.State)
+		}
+	}
+	return nil
+}
+
+// removeVersions Removes the version of the app from all referencing stages and releases
+func (refs *references) removeVersions(appName string) error {
+	for _, stage := range refs.stages {
+		delete(stage.Versions, appName)
+		if err := stage.Update(); err != nil {
+			return log.Errf(err, "Could not remove app %s from stage %s", appName, stage.Name)
+		}
+	}
+	for _, release := range refs.releases {
+		delete(release.Versions, appName)
+		if err := release.Update(); err != nil {
+			return log.Errf(err, "Could not remove app %s from release %s", appName, release.FullName())
+		}
+	}
+	return nil
+}
+
+// removeVersionOverrides Removes the version overrides of the app from all referencing targets and env classes,
+// other parameters of the app are kept
+func (refs *references) removeVersionOverrides(appName string) error {
+	for _, target := range refs.targetOverrides {
+		removeVersionOverride(target.Parameters, appName)
+		if err := target.Update(); err != nil {
+			return log.Errf(err, "Could not remove version override of app %s from target %s", appName, target.Name)
+		}
+	}
+	for _, env := range refs.envOverrides {
+		removeVersionOverride(env.Parameters, appName)
+		if err := env.Update(); err != nil {
+			return log.Errf(err, "Could not remove version override of app %s from env class %s", appName, env.Name)
+		}
+	}
+	return nil
+}
+
+// renameVersions Moves the version of the app to its new name in all referencing stages and releases
+func (refs *references) renameVersions(oldName string, newName string) error {
+	for _, stage := range refs.stages {
+		stage.Versions[newName] = stage.Versions[oldName]
+		delete(stage.Versions, oldName)
+		if err := stage.Update(); err != nil {
+			return log.Errf(err, "Could not rename app %s in stage %s", oldName, stage.Name)
+		}
+	}
+	for _, release := range refs.releases {
+		release.Versions[newName] = release.Versions[oldName]
+		delete(release.Versions, oldName)
+		if err := release.Update(); err != nil {
+			return log.Errf(err, "Could not rename app %s in release %s", oldName, release.FullName())
+		}
+	}
+	return nil
+}
+
+// replaceTargetClasses Replaces a class by another one in all targets found, keeping the order of the classes
+func (refs *references) replaceTargetClasses(oldClass string, newClass string) error {
+	for _, target := range refs.targets {
+		for i, class := range target.Classes {
+			if class == oldClass {
+				target.Classes[i] = newClass
+			}
+		}
+		if err := target.Update(); err != nil {
+			return log.Errf(err, "Could not update references in target %s", target.Name)
+		}
+	}
+	return nil
+}
+
+// removeTargetClasses Removes the referencing classes from all targets found
+func (refs *references) removeTargetClasses() error {
+	for _, target := range refs.targets {
+		classes := make([]string, 0, len(target.Classes))
+		for _, class := range target.Classes {
+			if !refs.classMatch(class) {
+				classes = append(classes, class)
+			}
+		}
+		target.Classes = classes
+		if err := target.Update(); err != nil {
+			return log.Errf(err, "Could not remove references from target %s", target.Name)
+		}
+	}
+	return nil
+}
+
+// findTargetReferences Returns all targets using a class matched by classMatch
+func findTargetReferences(classMatch func(class string) bool) ([]*Target, error) {
+	targets, err := readTargets()
+	if err != nil {
+		return nil, err
+	}
+	return filterTargetClasses(targets, classMatch), nil
+}
+
+func filterTargetClasses(targets []*Target, classMatch func(class string) bool) []*Target {
+	result := make([]*Target, 0)
+	for _, target := range targets {
+		for _, class := range target.Classes {
+			if classMatch(class) {
+				result = append(result, target)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func readTargets() ([]*Target, error) {
+	names, err := ListTargets()
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]*Target, 0, len(names))
+	for _, name := range names {
+		target := NewTarget(name)
+		if err = target.Read(); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// findVersionOverrides Returns the targets and env classes that override the version of the app in their parameters.
+//
+// The targets are taken from the targets passed in, so a target that also references the app through its classes
+// is only changed through a single instance.
+func findVersionOverrides(appName string, targets []*Target) ([]*Target, []*EnvClass, error) {
+	targetOverrides := make([]*Target, 0)
+	for _, target := range targets {
+		if hasVersionOverride(target.Parameters, appName) {
+			targetOverrides = append(targetOverrides, target)
+		}
+	}
+	names, err := ListEnvClasses()
+	if err != nil {
+		return nil, nil, err
+	}
+	envOverrides := make([]*EnvClass, 0)
+	for _, name := range names {
+		env := NewEnvClass(name)
+		if err = env.Read(); err != nil {
+			return nil, nil, err
+		}
+		if hasVersionOverride(env.Parameters, appName) {
+			envOverrides = append(envOverrides, env)
+		}
+	}
+	return targetOverrides, envOverrides, nil
+}
+
+// hasVersionOverride Returns true if the parameters contain a version override of the form 'APP: {version: VERSION}'
+func hasVersionOverride(parameters map[interface{}]interface{}, appName string) bool {
+	if props, ok := parameters[appName].(map[interface{}]interface{}); ok {
+		_, exists := props["version"]
+		return exists
+	}
+	return false
+}
+
+func removeVersionOverride(parameters map[interface{}]interface{}, appName string) {
+	if props, ok := parameters[appName].(map[interface{}]interface{}); ok {
+		delete(props, "version")
+		if len(props) == 0 {
+			delete(parameters, appName)
+		}
+	}
+}
+
+// findVersionReferences Returns all stages and releases, including stage releases, that contain a version of the app
+func findVersionReferences(appName string) ([]*Stage, []*Release, error) {
+	stages := make([]*Stage, 0)
+	names, err := ListStages()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		stage := NewStage(name)
+		if err = stage.Read(); err != nil {
+			return nil, nil, err
+		}
+		if _, exists := stage.Versions[appName]; exists {
+			stages = append(stages, stage)
+		}
+	}
+	releases := make([]*Release, 0)
+	for _, releaseType := range []ReleaseType{StageRelease, ProductRelease, HotFixRelease} {
+		if names, err = ListReleases(releaseType); err != nil {
+			return nil, nil, err
+		}
+		for _, name := range names {
+			release := NewRelease(name, releaseType)
+			if err = release.Read(); err != nil {
+				return nil, nil, err
+			}
+			if _, exists := release.Versions[appName]; exists {
+				releases = append(releases, release)
+			}
+		}
+	}
+	return stages, releases, nil
+}
+
+func classMatcher(classes ...string) func(class string) bool {
+	return func(class string) bool {
+		for _, c := range classes {
+			if class == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (app *App) findReferences() (*references, error) {
+	refs := &references{classMatch: classMatcher(app.className())}
+	var err error
+	if refs.stages, refs.releases, err = findVersionReferences(app.Name); err != nil {
+		return nil, err
+	}
+	targets, err := readTargets()
+	if err != nil {
+		return nil, err
+	}
+	refs.targets = filterTargetClasses(targets, refs.classMatch)
+	if refs.targetOverrides, refs.envOverrides, err = findVersionOverrides(app.Name, targets); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (group *AppGroup) findReferences() (*references, error) {
+	class := appPrefix + group.Name
+	refs := &references{classMatch: func(c string) bool {
+		return c == class || strings.HasPrefix(c, class+".")
+	}}
+	if err := group.Read(); err != nil {
+		return nil, err
+	}
+	refs.apps = group.Apps
+	var err error
+	if refs.targets, err = findTargetReferences(refs.classMatch); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (stage *Stage) findReferences() (*references, error) {
+	refs := &references{classMatch: classMatcher(
+		stageClassPrefix+stage.Name,
+		releaseClassPrefix+StageRelease.String()+"."+stage.Name,
+	)}
+	var err error
+	if refs.targets, err = findTargetReferences(refs.classMatch); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (release *Release) findReferences() (*references, error) {
+	refs := &references{classMatch: classMatcher(releaseClassPrefix + release.Type.String() + "." + release.Name)}
+	var err error
+	if refs.targets, err = findTargetReferences(refs.classMatch); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (env *EnvClass) findReferences() (*references, error) {
+	refs := &references{classMatch: classMatcher(envClassPrefix + env.Name)}
+	var err error
+	if refs.targets, err = findTargetReferences(refs.classMatch); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
@@ -0,0 +1,240 @@
+package gitops
+
+import (
+	"errors"
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"testing"
+)
+
+type ReferencesSuite struct {
+	suite.Suite
+}
+
+func (suite *ReferencesSuite) SetupTest() {
+	TestsSetupWorkingDir(suite.Suite)
+	CreateTestAppGroup(suite.Suite, "test")
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	CreateTestStage(suite.Suite, "alpha")
+	CreateTestRelease(suite.Suite, "alpha", StageRelease)
+	CreateTestRelease(suite.Suite, "my-release", ProductRelease)
+	CreateTestTarget(suite.Suite, "my-target")
+}
+
+func (suite *ReferencesSuite) TearDownTest() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *ReferencesSuite) TestAppReferences() {
+	suite.overrideVersion()
+	app, err := FindApp("app1")
+	r := suite.Require()
+	r.Nil(err)
+	refs, err := app.findReferences()
+	r.Nil(err)
+	r.Equal([]string{
+		"stage 'alpha'",
+		"release 'stage/alpha'",
+		"release 'product/my-release'",
+		"target 'my-target'",
+		"env class 'dev'",
+	}, refs.list())
+}
+
+// overrideVersion Overrides the version of app1 in my-target and the dev env class
+func (suite *ReferencesSuite) overrideVersion() {
+	r := suite.Require()
+	CreateTestEnvClass(suite.Suite, "dev")
+	env := NewEnvClass("dev")
+	r.Nil(env.Read())
+	env.Parameters["app1"] = map[interface{}]interface{}{"version": "1.0.1"}
+	r.Nil(env.Update())
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	target.Parameters["app1"] = map[interface{}]interface{}{"version": "1.0.2", "replicas": 2}
+	r.Nil(target.Update())
+}
+
+func (suite *ReferencesSuite) TestDeleteReferencedAppReturnsErr() {
+	app, _ := FindApp("app1")
+	err := app.Delete()
+	r := suite.Require()
+	r.Equal(ResourceInUseErr, err)
+	r.True(app.Exists())
+}
+
+func (suite *ReferencesSuite) TestDeleteAppCascade() {
+	target := NewTarget("my-target")
+	r := suite.Require()
+	r.Nil(target.Read())
+	target.AddClass("apps.test.app1")
+	r.Nil(target.Update())
+
+	app, _ := FindApp("app1")
+	r.Nil(app.DeleteCascade())
+	r.False(app.Exists())
+	group := NewAppGroup("test")
+	r.Nil(group.Read())
+	r.Len(group.Apps, 1)
+	r.Equal("app2", group.Apps[0].Name)
+	stage := NewStage("alpha")
+	r.Nil(stage.Read())
+	r.NotContains(stage.Versions, "app1")
+	release := NewRelease("my-release", ProductRelease)
+	r.Nil(release.Read())
+	r.NotContains(release.Versions, "app1")
+	target = NewTarget("my-target")
+	r.Nil(target.Read())
+	r.False(target.HasClass("apps.test.app1"))
+	r.True(target.HasClass("apps.test"))
+}
+
+func (suite *ReferencesSuite) TestDeleteAppCascadeFrozenReleaseReturnsErr() {
+	r := suite.Require()
+	r.Nil(NewRelease("my-release", ProductRelease).Finalize())
+	app, _ := FindApp("app1")
+	r.Equal(ReleaseFrozenErr, app.DeleteCascade())
+	r.True(app.Exists())
+	stage := NewStage("alpha")
+	r.Nil(stage.Read())
+	r.Contains(stage.Versions, "app1")
+}
+
+func (suite *ReferencesSuite) TestDeleteUnreferencedApp() {
+	r := suite.Require()
+	app := NewApp("app4", NewAppGroup("test"))
+	r.Nil(app.Create())
+	r.Nil(app.Delete())
+	r.False(app.Exists())
+	group := NewAppGroup("test")
+	r.Nil(group.Read())
+	r.Len(group.Apps, 2)
+}
+
+func (suite *ReferencesSuite) TestDeleteGroup() {
+	r := suite.Require()
+	group := NewAppGroup("test")
+	r.Equal(ResourceInUseErr, group.Delete())
+	r.Nil(NewAppGroup("test").DeleteCascade())
+	r.False(group.Exists())
+	r.NoDirExists(group.GetFolderPath())
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.False(target.HasClass("apps.test"))
+	stage := NewStage("alpha")
+	r.Nil(stage.Read())
+	r.Equal(map[string]string{"app3": "3.0.0"}, stage.Versions)
+}
+
+func (suite *ReferencesSuite) TestDeleteStage() {
+	r := suite.Require()
+	stage := NewStage("alpha")
+	r.Equal(ResourceInUseErr, stage.Delete())
+	r.True(stage.Exists())
+	r.Nil(stage.DeleteCascade())
+	r.False(stage.Exists())
+	r.False(NewRelease("alpha", StageRelease).Exists())
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.Equal([]string{"releases.product.my-release", "apps.test"}, target.Classes)
+}
+
+func (suite *ReferencesSuite) TestDeleteRelease() {
+	r := suite.Require()
+	release := NewRelease("my-release", ProductRelease)
+	r.Equal(ResourceInUseErr, release.Delete())
+	r.Nil(release.DeleteCascade())
+	r.False(release.Exists())
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.Equal([]string{"stages.alpha", "apps.test"}, target.Classes)
+
+	r.Equal(ResourceDoesNotExistErr, NewRelease("unknown", ProductRelease).Delete())
+}
+
+func (suite *ReferencesSuite) TestDeleteFrozenReleaseReturnsErr() {
+	r := suite.Require()
+	r.Nil(NewRelease("my-release", ProductRelease).Finalize())
+	release := NewRelease("my-release", ProductRelease)
+	r.Equal(ReleaseFrozenErr, release.Delete())
+	r.Equal(ReleaseFrozenErr, release.DeleteCascade())
+	r.True(release.Exists())
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.True(target.HasClass("releases.product.my-release"))
+}
+
+func (suite *ReferencesSuite) TestDeleteAppCascadeRemovesVersionOverrides() {
+	suite.overrideVersion()
+	app, _ := FindApp("app1")
+	r := suite.Require()
+	r.Equal(ResourceInUseErr, app.Delete())
+	r.Nil(app.DeleteCascade())
+	env := NewEnvClass("dev")
+	r.Nil(env.Read())
+	r.NotContains(env.Parameters, "app1")
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.Equal(map[interface{}]interface{}{"replicas": 2}, target.Parameters["app1"])
+}
+
+func (suite *ReferencesSuite) TestDeleteGroupCascadeRemovesVersionOverrides() {
+	suite.overrideVersion()
+	r := suite.Require()
+	r.Nil(NewAppGroup("test").DeleteCascade())
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	r.False(target.HasClass("apps.test"))
+	r.Equal(map[interface{}]interface{}{"replicas": 2}, target.Parameters["app1"])
+}
+
+func (suite *ReferencesSuite) TestChangeFilesRestoresFilesOnErr() {
+	r := suite.Require()
+	stage := NewStage("alpha")
+	created := NewStage("beta")
+	original, err := os.ReadFile(stage.GetFilePath())
+	r.Nil(err)
+	failed := errors.New("failed")
+	err = changeFiles([]string{stage.GetFilePath(), created.GetFilePath()}, func() error {
+		r.Nil(os.WriteFile(stage.GetFilePath(), []byte("changed"), 0644))
+		r.Nil(created.Create())
+		return failed
+	})
+	r.Equal(failed, err)
+	restored, err := os.ReadFile(stage.GetFilePath())
+	r.Nil(err)
+	r.Equal(original, restored)
+	r.False(created.Exists())
+}
+
+func (suite *ReferencesSuite) TestDeleteEnvClass() {
+	r := suite.Require()
+	CreateTestEnvClass(suite.Suite, "dev")
+	target := NewTarget("my-target")
+	r.Nil(target.Read())
+	target.AddEnvClass(NewEnvClass("dev"))
+	r.Nil(target.Update())
+	env := NewEnvClass("dev")
+	r.Equal(ResourceInUseErr, env.Delete())
+	r.True(env.Exists())
+	target = NewTarget("my-target")
+	r.Nil(target.Read())
+	r.True(target.RemoveClass("env.dev"))
+	r.Nil(target.Update())
+	r.Nil(env.Delete())
+	r.False(env.Exists())
+}
+
+func (suite *ReferencesSuite) TestDeleteTarget() {
+	r := suite.Require()
+	target := NewTarget("my-target")
+	r.Nil(target.Delete())
+	r.False(target.Exists())
+	r.Equal(ResourceDoesNotExistErr, target.Delete())
+}
+
+func TestReferencesTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferencesSuite))
+}
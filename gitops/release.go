@@ -1,24 +1,31 @@
 package gitops
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"gosh/log"
 	"gosh/util"
 	"path/filepath"
 	"strings"
+	"text/template"
 )
 
 const (
 	releasesPath = kapitanClassesPath + "releases"
+	// releaseStatesKey The parameter that holds the state of releases, kept out of the app entries of the release
+	releaseStatesKey = "release_states"
 )
 
 var (
 	InvalidFullReleaseNameErr = errors.New("invalid release name, must be 'type/name' and type 'stage' is reserved")
+	ReleaseFrozenErr          = errors.New("release is frozen and can no longer be changed")
 )
 
 type Release struct {
 	Type     ReleaseType
 	Name     string
+	State    ReleaseState
 	Versions map[string]string
 	_read    bool
 }
@@ -32,7 +39,7 @@ func (release *Release) setInitialized() {
 }
 
 func NewRelease(name string, releaseType ReleaseType) *Release {
-	return &Release{Name: name, Type: releaseType, Versions: map[string]string{}}
+	return &Release{Name: name, Type: releaseType, State: DraftRelease, Versions: map[string]string{}}
 }
 
 func NewReleaseFromFullName(fullName string) (*Release, error) {
@@ -86,6 +93,48 @@ func (release *Release) CreateFromRelease(releaseName string) error {
 	}
 }
 
+// ListReleases Returns the sorted names of all releases of a type in the deployment repository
+func ListReleases(releaseType ReleaseType) ([]string, error) {
+	return listResourceNames(filepath.Join(util.Context.WorkingDir, releasesPath, releaseType.String()))
+}
+
+// Delete Deletes the release, fails with ResourceInUseErr when targets still use it
+func (release *Release) Delete() error {
+	return release.delete(false)
+}
+
+// DeleteCascade Deletes the release like Delete, first removing it from all targets
+func (release *Release) DeleteCascade() error {
+	return release.delete(true)
+}
+
+func (release *Release) delete(cascade bool) error {
+	if !release.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The release '%s' does not exist", release.FullName())
+	}
+	if err := release.Read(); err != nil {
+		return err
+	}
+	if release.State.IsFrozen() {
+		return log.Errf(ReleaseFrozenErr, "Release %s is %s and can no longer be deleted", release.FullName(), release.State)
+	}
+	refs, err := release.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to release %s", release.FullName())
+	}
+	if !cascade {
+		if err = refs.inUseErr(release); err != nil {
+			return err
+		}
+	}
+	return changeFiles(append(refs.files(), release.GetFilePath()), func() error {
+		if err := refs.removeTargetClasses(); err != nil {
+			return err
+		}
+		return deleteResource(release)
+	})
+}
+
 func (release *Release) Read() error {
 	return read(release)
 }
@@ -94,8 +143,55 @@ func (release *Release) Update() error {
 	return update(release)
 }
 
+// FullName Returns the name of the release including its type, e.g. 'product/2021.R1'
+func (release *Release) FullName() string {
+	return release.Type.String() + "/" + release.Name
+}
+
+// TagName Returns the name of the git tag that marks the finalized snapshot of this release.
+//
+// The name is rendered from the configured tag format, which defaults to the full release name, e.g. 'product/2021.R1'
+func (release *Release) TagName() (string, error) {
+	format := util.Config.Releases.TagFormat
+	if format == "" {
+		format = util.DefaultReleaseTagFormat
+	}
+	t, err := template.New("tag").Parse(format)
+	if err != nil {
+		return "", log.Errf(err, "Invalid release tag format %s", format)
+	}
+	result := new(bytes.Buffer)
+	if err = t.Execute(result, map[string]string{"Type": release.Type.String(), "Name": release.Name}); err != nil {
+		return "", log.Errf(err, "Could not render release tag format %s for release %s", format, release.FullName())
+	}
+	return result.String(), nil
+}
+
+// SetState Moves the release to a new lifecycle state, once final a release can only become eol
+func (release *Release) SetState(state ReleaseState) error {
+	if err := release.Read(); err != nil {
+		return err
+	}
+	if release.Type == StageRelease {
+		return log.Errf(InvalidReleaseStateErr, "Stage release %s follows its stage and has no lifecycle", release.Name)
+	}
+	if !release.State.canTransitionTo(state) {
+		return log.Errf(InvalidReleaseStateErr, "Release %s cannot go from state %s to %s", release.FullName(), release.State, state)
+	}
+	release.State = state
+	return release.Update()
+}
+
+// Finalize Freezes the release so its versions become a static snapshot that can no longer be updated
+func (release *Release) Finalize() error {
+	return release.SetState(FinalRelease)
+}
+
 func (release *Release) UpdateVersion(appName string, version string) error {
 	if err := release.Read(); err == nil {
+		if release.State.IsFrozen() {
+			return log.Errf(ReleaseFrozenErr, "Release %s is %s, versions can no longer be updated", release.FullName(), release.State)
+		}
 		if app, err := FindApp(appName); err == nil {
 			release.Versions[app.Name] = version
 			return release.Update()
@@ -116,6 +212,9 @@ func (release *Release) mapToKapitanFile() *kapitanFile {
 	for key, value := range release.Versions {
 		props[key] = map[string]string{"version": value}
 	}
+	if release.State != 0 && release.State != DraftRelease {
+		f.Parameters[releaseStatesKey] = map[string]string{release.Name: release.State.String()}
+	}
 	log.Tracef("Mapped release %s to kapitan file, result: %+v", release.Name, f)
 	return f
 }
@@ -123,10 +222,22 @@ func (release *Release) mapToKapitanFile() *kapitanFile {
 func (release *Release) mapFromKapitanFile(f *kapitanFile) {
 	log.Tracef("Mapping release %s from kapitan file %+v", release.Name, f)
 	release.Versions = make(map[string]string, 0)
-	if properties, exists := f.Parameters[release.Name]; exists {
-		for key, value := range properties.(map[interface{}]interface{}) {
-			if version, exists := value.(map[interface{}]interface{})["version"]; exists {
-				release.Versions[key.(string)] = version.(string)
+	release.State = DraftRelease
+	if properties, exists := f.Parameters[release.Name].(map[interface{}]interface{}); exists {
+		for key, value := range properties {
+			if props, ok := value.(map[interface{}]interface{}); ok {
+				if version, exists := props["version"]; exists {
+					release.Versions[key.(string)] = fmt.Sprint(version)
+				}
+			}
+		}
+	}
+	if states, exists := f.Parameters[releaseStatesKey].(map[interface{}]interface{}); exists {
+		if value, exists := states[release.Name]; exists {
+			if state, err := NewReleaseState(fmt.Sprint(value)); err == nil {
+				release.State = state
+			} else {
+				log.Warnf("release '%s' has unsupported state '%v', using draft", release.Name, value)
 			}
 		}
 	}
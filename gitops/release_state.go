@@ -0,0 +1,58 @@
+package gitops
+
+import "errors"
+
+type ReleaseState int
+
+const (
+	DraftRelease ReleaseState = iota + 1
+	ValidatingRelease
+	FinalRelease
+	EndOfLifeRelease
+)
+
+var (
+	UnsupportedReleaseStateErr = errors.New("unsupported release state")
+	InvalidReleaseStateErr     = errors.New("invalid release state transition")
+)
+
+func (s ReleaseState) String() string {
+	return [...]string{"draft", "validating", "final", "eol"}[s-1]
+}
+
+func (s ReleaseState) EnumIndex() int {
+	return int(s)
+}
+
+// IsFrozen Returns true if releases in this state are static snapshots that can no longer be changed
+func (s ReleaseState) IsFrozen() bool {
+	return s == FinalRelease || s == EndOfLifeRelease
+}
+
+// canTransitionTo Releases move forward through draft, validating, final and eol. Draft and validating can be switched
+// back and forth, but once final a release can only become eol
+func (s ReleaseState) canTransitionTo(state ReleaseState) bool {
+	switch s {
+	case DraftRelease, ValidatingRelease:
+		return true
+	case FinalRelease:
+		return state == FinalRelease || state == EndOfLifeRelease
+	case EndOfLifeRelease:
+		return state == EndOfLifeRelease
+	}
+	return false
+}
+
+func NewReleaseState(value string) (ReleaseState, error) {
+	switch value {
+	case "draft":
+		return DraftRelease, nil
+	case "validating":
+		return ValidatingRelease, nil
+	case "final":
+		return FinalRelease, nil
+	case "eol":
+		return EndOfLifeRelease, nil
+	}
+	return 0, UnsupportedReleaseStateErr
+}
@@ -109,6 +109,58 @@ func (suite *ReleaseSuite) TestUpdate() {
 	r.Equal("4.0.0", release.Versions["my-app"])
 }
 
+func (suite *ReleaseSuite) TestFinalize() {
+	r := suite.Require()
+	CreateTestRelease(suite.Suite, "to-finalize", ProductRelease)
+	CreateTestAppGroup(suite.Suite, "test")
+	CreateTestApp(suite.Suite, "app1", "test")
+	release := NewRelease("to-finalize", ProductRelease)
+	r.Nil(release.Read())
+	r.Equal(DraftRelease, release.State)
+	r.Nil(release.SetState(ValidatingRelease))
+	r.Nil(release.UpdateVersion("app1", "1.1.0"))
+	r.Nil(release.Finalize())
+
+	release = NewRelease("to-finalize", ProductRelease)
+	r.Nil(release.Read())
+	r.Equal(FinalRelease, release.State)
+	r.Len(release.Versions, 3)
+	r.Equal("1.1.0", release.Versions["app1"])
+	f, err := ReadKapitanFile(release.GetFilePath())
+	r.Nil(err)
+	r.Equal(map[interface{}]interface{}{"to-finalize": "final"}, f.Parameters[releaseStatesKey])
+	for _, value := range f.Parameters["to-finalize"].(map[interface{}]interface{}) {
+		r.IsType(map[interface{}]interface{}{}, value)
+	}
+	tag, err := release.TagName()
+	r.Nil(err)
+	r.Equal("product/to-finalize", tag)
+
+	err = release.UpdateVersion("app1", "1.2.0")
+	r.Equal(ReleaseFrozenErr, err)
+	err = release.SetState(DraftRelease)
+	r.Equal(InvalidReleaseStateErr, err)
+	r.Nil(release.SetState(EndOfLifeRelease))
+	err = release.UpdateVersion("app1", "1.2.0")
+	r.Equal(ReleaseFrozenErr, err)
+}
+
+func (suite *ReleaseSuite) TestTagNameFormat() {
+	util.Config.Releases.TagFormat = "releases/{{.Type}}-{{.Name}}"
+	defer func() { util.Config.Releases.TagFormat = "" }()
+	tag, err := NewRelease("2021.R1", HotFixRelease).TagName()
+	r := suite.Require()
+	r.Nil(err)
+	r.Equal("releases/hotfix-2021.R1", tag)
+}
+
+func (suite *ReleaseSuite) TestFinalizeStageReleaseReturnsErr() {
+	CreateTestRelease(suite.Suite, "alpha", StageRelease)
+	err := NewRelease("alpha", StageRelease).Finalize()
+	r := suite.Require()
+	r.Equal(InvalidReleaseStateErr, err)
+}
+
 func TestReleaseTestSuite(t *testing.T) {
 	suite.Run(t, new(ReleaseSuite))
 }
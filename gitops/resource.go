@@ -5,12 +5,15 @@ import (
 	"gosh/log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 type Resource interface {
 	Create() error
 	Read() error
 	Update() error
+	Delete() error
 	Exists() bool
 	GetFilePath() string
 	mapFromKapitanFile(f *kapitanFile)
@@ -26,6 +29,7 @@ var (
 	ResourceDoesNotExistErr          = errors.New("resource does not exist")
 	ResourceAlreadyExistsErr         = errors.New("resource already exist")
 	ResourceUpdatedWithoutReadingErr = errors.New("the resource was updated before being read from disk, this might lead to data loss")
+	ResourceInUseErr                 = errors.New("resource is still referenced")
 )
 
 func exists(resource Resource) bool {
@@ -101,3 +105,79 @@ func update(resource Resource) error {
 		return log.Errf(err, "Could not update %s '%s'", resource.getResourceType(), resource.getResourceName())
 	}
 }
+
+func deleteResource(resource Resource) error {
+	log.Tracef("Delete %s with input: %+v", resource.getResourceName(), resource)
+	if resource == nil || !resource.isValid() {
+		return log.Errf(ValidationErr, "Invalid struct, use constructor to create one")
+	}
+	if !resource.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The %s '%s' does not exist", resource.getResourceType(), resource.getResourceName())
+	}
+	if err := os.Remove(resource.GetFilePath()); err == nil {
+		log.Infof("Deleted %s '%s'", resource.getResourceType(), resource.getResourceName())
+		return nil
+	} else {
+		return log.Errf(err, "Could not delete %s '%s'", resource.getResourceType(), resource.getResourceName())
+	}
+}
+
+// changeFiles Runs a change spanning several files, the files are restored to their original contents when it fails.
+//
+// Files that did not exist before the change are removed again, so resources created by the change are undone as well.
+func changeFiles(paths []string, change func() error) error {
+	type original struct {
+		data   []byte
+		exists bool
+	}
+	originals := map[string]original{}
+	for _, path := range paths {
+		if _, done := originals[path]; done {
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			originals[path] = original{data: data, exists: true}
+		} else if os.IsNotExist(err) {
+			originals[path] = original{}
+		} else {
+			return log.Errf(err, "Could not read %s before changing it", path)
+		}
+	}
+	err := change()
+	if err != nil {
+		log.Warnf("Restoring %d files after a failed change", len(originals))
+		for path, o := range originals {
+			var restoreErr error
+			if !o.exists {
+				if restoreErr = os.Remove(path); os.IsNotExist(restoreErr) {
+					restoreErr = nil
+				}
+			} else if restoreErr = os.MkdirAll(filepath.Dir(path), 0755); restoreErr == nil {
+				restoreErr = os.WriteFile(path, o.data, 0644)
+			}
+			if restoreErr != nil {
+				log.Warnf("Could not restore %s: %v", path, restoreErr)
+			}
+		}
+	}
+	return err
+}
+
+// listResourceNames Returns the sorted names of all kapitan files in a directory, a missing directory has no resources
+func listResourceNames(dir string) ([]string, error) {
+	names := make([]string, 0)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return names, nil
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), kapitanFileExt) {
+				names = append(names, strings.TrimSuffix(entry.Name(), kapitanFileExt))
+			}
+		}
+		sort.Strings(names)
+		return names, nil
+	} else {
+		return nil, log.Errf(err, "Could not list resources in %s", dir)
+	}
+}
@@ -0,0 +1,109 @@
+package gitops
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	InvalidSemVerErr = errors.New("invalid semantic version, expected MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]")
+	semVerRegexp     = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+)
+
+// SemVer A semantic version as specified by https://semver.org, an optional 'v' prefix is allowed
+type SemVer struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	PreRelease []string
+	Build      string
+}
+
+func ParseSemVer(version string) (*SemVer, error) {
+	match := semVerRegexp.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return nil, InvalidSemVerErr
+	}
+	v := &SemVer{Build: match[5]}
+	var err error
+	if v.Major, err = strconv.ParseUint(match[1], 10, 64); err != nil {
+		return nil, InvalidSemVerErr
+	}
+	if v.Minor, err = strconv.ParseUint(match[2], 10, 64); err != nil {
+		return nil, InvalidSemVerErr
+	}
+	if v.Patch, err = strconv.ParseUint(match[3], 10, 64); err != nil {
+		return nil, InvalidSemVerErr
+	}
+	if match[4] != "" {
+		v.PreRelease = strings.Split(match[4], ".")
+	}
+	return v, nil
+}
+
+func (v *SemVer) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if len(v.PreRelease) > 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare Returns -1, 0 or 1 when the version has a lower, equal or higher precedence than the other version.
+//
+// Build metadata is ignored and a pre-release has a lower precedence than the release itself, e.g. 1.0.0-rc.1 < 1.0.0
+func (v *SemVer) Compare(other *SemVer) int {
+	if c := compareUint(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.PreRelease) == 0 && len(other.PreRelease) == 0:
+		return 0
+	case len(v.PreRelease) == 0:
+		return 1
+	case len(other.PreRelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.PreRelease) && i < len(other.PreRelease); i++ {
+		if c := comparePreReleaseIdentifier(v.PreRelease[i], other.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.PreRelease)), uint64(len(other.PreRelease)))
+}
+
+// comparePreReleaseIdentifier Numeric identifiers are compared numerically and have a lower precedence than alphanumeric ones
+func comparePreReleaseIdentifier(a string, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a uint64, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
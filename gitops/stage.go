@@ -1,9 +1,12 @@
 package gitops
 
 import (
+	"errors"
+	"fmt"
 	"gosh/log"
 	"gosh/util"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -11,6 +14,10 @@ const (
 	stagesPath = kapitanClassesPath + "stages"
 )
 
+var (
+	StagePipelineOrderErr = errors.New("version is not present in the previous stage of the stage pipeline")
+)
+
 type Stage struct {
 	Name     string
 	Versions map[string]string
@@ -29,9 +36,57 @@ func NewStage(name string) *Stage {
 	return &Stage{Name: strings.ToLower(name), Versions: map[string]string{}}
 }
 
+// UpdateVersion Updates the version of an app and the associated stage release.
+//
+// When a stage pipeline is configured, the version must be present in the previous stage of the pipeline.
 func (stage *Stage) UpdateVersion(appName string, version string) error {
+	return stage.updateVersion(appName, version, false)
+}
+
+// ForceUpdateVersion Updates the version of an app like UpdateVersion, without enforcing the stage pipeline order
+func (stage *Stage) ForceUpdateVersion(appName string, version string) error {
+	return stage.updateVersion(appName, version, true)
+}
+
+// Rollback Sets the version of an app back to a version found in the history of the stage, keeping the stage release in sync.
+//
+// The stage pipeline order is not enforced, the version was already deployed to this stage before.
+func (stage *Stage) Rollback(appName string, version string) error {
+	return stage.updateVersion(appName, version, true)
+}
+
+// PreviousStage Returns the stage before this one in the configured stage pipeline, or nil if there is none
+func (stage *Stage) PreviousStage() *Stage {
+	for i, name := range util.Config.Stages.Pipeline {
+		if name == stage.Name && i > 0 {
+			return NewStage(util.Config.Stages.Pipeline[i-1])
+		}
+	}
+	return nil
+}
+
+func (stage *Stage) checkPipelineOrder(appName string, version string) error {
+	previous := stage.PreviousStage()
+	if previous == nil {
+		return nil
+	}
+	if err := previous.Read(); err != nil {
+		return log.Errf(err, "Could not read previous stage %s of stage %s", previous.Name, stage.Name)
+	}
+	if previousVersion, exists := previous.Versions[appName]; !exists || previousVersion != version {
+		return log.Errf(StagePipelineOrderErr, "App %s version %s is not present in stage %s, promote it there first or use force", appName, version, previous.Name)
+	}
+	return nil
+}
+
+func (stage *Stage) updateVersion(appName string, version string, force bool) error {
 	if err := stage.Read(); err == nil {
 		if app, err := FindApp(appName); err == nil {
+			if force {
+				log.Warnf("Forcing version %s of app %s in stage %s, stage pipeline order is not enforced", version, app.Name, stage.Name)
+			} else if err = stage.checkPipelineOrder(app.Name, version); err != nil {
+				return err
+			}
 			stage.Versions[app.Name] = version
 			if err = stage.Update(); err == nil {
 				release := NewRelease(stage.Name, StageRelease)
@@ -52,6 +107,100 @@ func (stage *Stage) UpdateVersion(appName string, version string) error {
 
 }
 
+// UpdateVersions Updates the versions of multiple apps and the associated stage release at once.
+//
+// All apps must exist and, unless force is set, respect the stage pipeline order before anything is written, so either
+// all versions are updated or none are. Returns the versions that were changed.
+func (stage *Stage) UpdateVersions(versions map[string]string, force bool) (map[string]string, error) {
+	if err := stage.Read(); err != nil {
+		return nil, err
+	}
+	release := NewRelease(stage.Name, StageRelease)
+	if err := release.Read(); err != nil {
+		return nil, log.Errf(err, "Could not read stage release %s", release.Name)
+	}
+	if release.State.IsFrozen() {
+		return nil, log.Errf(ReleaseFrozenErr, "Release %s is %s, versions can no longer be updated", release.FullName(), release.State)
+	}
+	var missing []string
+	for appName := range versions {
+		if _, err := FindApp(appName); err != nil {
+			missing = append(missing, appName)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, log.Errf(ResourceDoesNotExistErr, "apps with name %s do not exist", strings.Join(missing, ", "))
+	}
+	changed := map[string]string{}
+	for appName, version := range versions {
+		if current, exists := stage.Versions[appName]; exists && current == version {
+			log.Debugf("App %s already has version %s in stage %s, skipping", appName, version, stage.Name)
+			continue
+		}
+		if force {
+			log.Warnf("Forcing version %s of app %s in stage %s, stage pipeline order is not enforced", version, appName, stage.Name)
+		} else if err := stage.checkPipelineOrder(appName, version); err != nil {
+			return nil, err
+		}
+		changed[appName] = version
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+	previous := make(map[string]string, len(stage.Versions))
+	for appName, version := range stage.Versions {
+		previous[appName] = version
+	}
+	for appName, version := range changed {
+		stage.Versions[appName] = version
+		release.Versions[appName] = version
+	}
+	if err := stage.Update(); err != nil {
+		return nil, log.Errf(err, "Could not update stage %s", stage.Name)
+	}
+	if err := release.Update(); err != nil {
+		stage.Versions = previous
+		if restoreErr := stage.Update(); restoreErr != nil {
+			log.Warnf("Could not restore the versions of stage %s: %v", stage.Name, restoreErr)
+		}
+		return nil, log.Errf(err, "Could not update stage release %s", release.Name)
+	}
+	return changed, nil
+}
+
+// Promote Copies the versions of the apps in the source stage to this stage, keeping the associated stage release in sync.
+//
+// Use group and app to filter which versions are promoted the same way as GetVersions, when neither is set all
+// versions of the source stage are promoted. Use force to skip the stage pipeline order check. All versions are
+// checked before anything is written, see UpdateVersions, so either all versions are promoted or none are.
+// Returns the versions that were changed.
+func (stage *Stage) Promote(from *Stage, group string, app string, force bool) (map[string]string, error) {
+	if err := from.Read(); err != nil {
+		return nil, log.Errf(err, "Could not read source stage %s", from.Name)
+	}
+	if err := stage.Read(); err != nil {
+		return nil, log.Errf(err, "Could not read target stage %s", stage.Name)
+	}
+	versions := from.GetVersions(group, app)
+	if app != "" && len(versions) == 0 {
+		return nil, log.Errf(ResourceDoesNotExistErr, "App %s has no version in stage %s", app, from.Name)
+	}
+	promoted, err := stage.UpdateVersions(versions, force)
+	if err != nil {
+		return nil, log.Errf(err, "Could not promote stage %s to stage %s", from.Name, stage.Name)
+	}
+	apps := make([]string, 0, len(promoted))
+	for appName := range promoted {
+		apps = append(apps, appName)
+	}
+	sort.Strings(apps)
+	for _, appName := range apps {
+		log.Infof("Promoted app %s version %s from stage %s to stage %s", appName, promoted[appName], from.Name, stage.Name)
+	}
+	return promoted, nil
+}
+
 func (stage *Stage) Create() error {
 	if err := create(stage); err == nil {
 		stageRelease := NewRelease(stage.Name, StageRelease)
@@ -71,6 +220,49 @@ func (stage *Stage) Create() error {
 	}
 }
 
+// ListStages Returns the sorted names of all stages in the deployment repository
+func ListStages() ([]string, error) {
+	return listResourceNames(filepath.Join(util.Context.WorkingDir, stagesPath))
+}
+
+// Delete Deletes the stage and its stage release, fails with ResourceInUseErr when targets still use either of them
+func (stage *Stage) Delete() error {
+	return stage.delete(false)
+}
+
+// DeleteCascade Deletes the stage and its stage release like Delete, first removing them from all targets
+func (stage *Stage) DeleteCascade() error {
+	return stage.delete(true)
+}
+
+func (stage *Stage) delete(cascade bool) error {
+	if !stage.Exists() {
+		return log.Errf(ResourceDoesNotExistErr, "The stage '%s' does not exist", stage.Name)
+	}
+	refs, err := stage.findReferences()
+	if err != nil {
+		return log.Errf(err, "Could not find references to stage %s", stage.Name)
+	}
+	if !cascade {
+		if err = refs.inUseErr(stage); err != nil {
+			return err
+		}
+	}
+	release := NewRelease(stage.Name, StageRelease)
+	return changeFiles(append(refs.files(), stage.GetFilePath(), release.GetFilePath()), func() error {
+		if err := refs.removeTargetClasses(); err != nil {
+			return err
+		}
+		if err := deleteResource(stage); err != nil {
+			return err
+		}
+		if release.Exists() {
+			return deleteResource(release)
+		}
+		return nil
+	})
+}
+
 func (stage *Stage) Read() error {
 	return read(stage)
 }
@@ -96,9 +288,11 @@ func (stage *Stage) mapToKapitanFile() *kapitanFile {
 func (stage *Stage) mapFromKapitanFile(f *kapitanFile) {
 	log.Tracef("Mapping stage %s from kapitan file %+v", stage.Name, f)
 	stage.Versions = make(map[string]string, 0)
-	if properties, exists := f.Parameters[stage.Name]; exists && properties != nil {
-		for key, value := range properties.(map[interface{}]interface{}) {
-			stage.Versions[key.(string)] = value.(string)
+	if properties, exists := f.Parameters[stage.Name].(map[interface{}]interface{}); exists {
+		for key, value := range properties {
+			if value != nil {
+				stage.Versions[fmt.Sprint(key)] = fmt.Sprint(value)
+			}
 		}
 	}
 	log.Tracef("Mapped stage %s from kapitan file, result: %+v", stage.Name, stage)
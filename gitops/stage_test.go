@@ -106,6 +106,160 @@ func (suite *StageSuite) TestUpdate_NotReadFirst() {
 	r.Equal(ResourceUpdatedWithoutReadingErr, err)
 }
 
+func (suite *StageSuite) TestPromote() {
+	r := suite.Require()
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	CreateTestApp(suite.Suite, "app3", "test")
+	CreateTestStage(suite.Suite, "tested")
+	published := NewStage("published")
+	published.Versions["app1"] = "1.0.0"
+	r.Nil(published.Create())
+
+	promoted, err := NewStage("published").Promote(NewStage("tested"), "", "app2", false)
+	r.Nil(err)
+	r.Equal(map[string]string{"app2": "2.0.0"}, promoted)
+
+	promoted, err = NewStage("published").Promote(NewStage("tested"), "", "", false)
+	r.Nil(err)
+	r.Equal(map[string]string{"app3": "3.0.0"}, promoted)
+
+	stage := NewStage("published")
+	r.Nil(stage.Read())
+	r.Equal(map[string]string{"app1": "1.0.0", "app2": "2.0.0", "app3": "3.0.0"}, stage.Versions)
+	release := NewRelease("published", StageRelease)
+	r.Nil(release.Read())
+	r.Equal(stage.Versions, release.Versions)
+}
+
+func (suite *StageSuite) TestPromoteUnknownApp() {
+	CreateTestStage(suite.Suite, "tested")
+	_, err := NewStage("alpha").Promote(NewStage("tested"), "", "unknown", false)
+	r := suite.Require()
+	r.NotNil(err)
+	r.Equal(ResourceDoesNotExistErr, err)
+}
+
+func (suite *StageSuite) TestUpdateVersionEnforcesPipelineOrder() {
+	r := suite.Require()
+	util.Config.Stages.Pipeline = []string{"ci", "qa", "prod"}
+	defer func() { util.Config.Stages.Pipeline = []string{} }()
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestStage(suite.Suite, "ci")
+	CreateTestRelease(suite.Suite, "ci", StageRelease)
+	for _, name := range []string{"qa", "prod"} {
+		if stage := NewStage(name); !stage.Exists() {
+			r.Nil(stage.Create())
+		}
+	}
+
+	err := NewStage("prod").UpdateVersion("app1", "1.0.0")
+	r.Equal(StagePipelineOrderErr, err)
+	err = NewStage("qa").UpdateVersion("app1", "1.1.0")
+	r.Equal(StagePipelineOrderErr, err)
+	err = NewStage("qa").UpdateVersion("app1", "1.0.0")
+	r.Nil(err)
+	err = NewStage("prod").UpdateVersion("app1", "1.0.0")
+	r.Nil(err)
+
+	_, err = NewStage("prod").Promote(NewStage("ci"), "", "app2", false)
+	r.Equal(StagePipelineOrderErr, err)
+	promoted, err := NewStage("prod").Promote(NewStage("ci"), "", "app2", true)
+	r.Nil(err)
+	r.Equal(map[string]string{"app2": "2.0.0"}, promoted)
+
+	err = NewStage("ci").UpdateVersion("app1", "1.2.0")
+	r.Nil(err)
+}
+
+func (suite *StageSuite) TestRollbackIgnoresPipelineOrder() {
+	r := suite.Require()
+	util.Config.Stages.Pipeline = []string{"dev", "staging"}
+	defer func() { util.Config.Stages.Pipeline = []string{} }()
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestStage(suite.Suite, "dev")
+	CreateTestStage(suite.Suite, "staging")
+	CreateTestRelease(suite.Suite, "staging", StageRelease)
+
+	r.Equal(StagePipelineOrderErr, NewStage("staging").UpdateVersion("app1", "0.9.0"))
+	r.Nil(NewStage("staging").Rollback("app1", "0.9.0"))
+	release := NewRelease("staging", StageRelease)
+	r.Nil(release.Read())
+	r.Equal("0.9.0", release.Versions["app1"])
+}
+
+func (suite *StageSuite) TestUpdateVersionsIsAtomic() {
+	r := suite.Require()
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	CreateTestStage(suite.Suite, "batch")
+	CreateTestRelease(suite.Suite, "batch", StageRelease)
+
+	_, err := NewStage("batch").UpdateVersions(map[string]string{"app1": "1.1.0", "unknown1": "1.0.0", "unknown2": "1.0.0"}, false)
+	r.Equal(ResourceDoesNotExistErr, err)
+	stage := NewStage("batch")
+	r.Nil(stage.Read())
+	r.Equal("1.0.0", stage.Versions["app1"])
+
+	changed, err := NewStage("batch").UpdateVersions(map[string]string{"app1": "1.1.0", "app2": "2.0.0"}, false)
+	r.Nil(err)
+	r.Equal(map[string]string{"app1": "1.1.0"}, changed)
+	stage = NewStage("batch")
+	r.Nil(stage.Read())
+	r.Equal(map[string]string{"app1": "1.1.0", "app2": "2.0.0", "app3": "3.0.0"}, stage.Versions)
+	release := NewRelease("batch", StageRelease)
+	r.Nil(release.Read())
+	r.Equal("1.1.0", release.Versions["app1"])
+}
+
+func (suite *StageSuite) TestUpdateVersionsEnforcesPipelineOrder() {
+	r := suite.Require()
+	util.Config.Stages.Pipeline = []string{"build", "verify"}
+	defer func() { util.Config.Stages.Pipeline = []string{} }()
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	CreateTestStage(suite.Suite, "build")
+	CreateTestStage(suite.Suite, "verify")
+	CreateTestRelease(suite.Suite, "verify", StageRelease)
+
+	_, err := NewStage("verify").UpdateVersions(map[string]string{"app1": "1.0.0", "app2": "2.1.0"}, false)
+	r.Equal(StagePipelineOrderErr, err)
+	changed, err := NewStage("verify").UpdateVersions(map[string]string{"app1": "1.0.0", "app2": "2.1.0"}, true)
+	r.Nil(err)
+	r.Equal(map[string]string{"app2": "2.1.0"}, changed)
+}
+
+func (suite *StageSuite) TestPromoteIsAtomic() {
+	r := suite.Require()
+	util.Config.Stages.Pipeline = []string{"build", "verify"}
+	defer func() { util.Config.Stages.Pipeline = []string{} }()
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	CreateTestStage(suite.Suite, "build")
+	CreateTestStage(suite.Suite, "verify")
+	CreateTestStage(suite.Suite, "hotfix")
+	for _, name := range []string{"build", "verify", "hotfix"} {
+		CreateTestRelease(suite.Suite, name, StageRelease)
+	}
+	r.Nil(NewStage("build").UpdateVersion("app1", "1.1.0"))
+	r.Nil(NewStage("hotfix").UpdateVersion("app1", "1.1.0"))
+	r.Nil(NewStage("hotfix").UpdateVersion("app2", "2.1.0"))
+
+	_, err := NewStage("verify").Promote(NewStage("hotfix"), "", "", false)
+	r.Equal(StagePipelineOrderErr, err)
+	stage := NewStage("verify")
+	r.Nil(stage.Read())
+	r.Equal("1.0.0", stage.Versions["app1"])
+	r.Equal("2.0.0", stage.Versions["app2"])
+	release := NewRelease("verify", StageRelease)
+	r.Nil(release.Read())
+	r.Equal("1.0.0", release.Versions["app1"])
+
+	promoted, err := NewStage("verify").Promote(NewStage("hotfix"), "", "app1", false)
+	r.Nil(err)
+	r.Equal(map[string]string{"app1": "1.1.0"}, promoted)
+}
+
 func TestStageTestSuite(t *testing.T) {
 	suite.Run(t, new(StageSuite))
 }
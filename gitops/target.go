@@ -0,0 +1,175 @@
+package gitops
+
+import (
+	"gosh/log"
+	"gosh/util"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	targetsPath        = "inventory/targets"
+	stageClassPrefix   = "stages."
+	releaseClassPrefix = "releases."
+)
+
+type Target struct {
+	Name       string
+	Classes    []string
+	Parameters map[interface{}]interface{}
+	resolved   map[string]string
+	_read      bool
+}
+
+func (target *Target) initialized() bool {
+	return target._read
+}
+
+func (target *Target) setInitialized() {
+	target._read = true
+}
+
+func NewTarget(name string) *Target {
+	return &Target{Name: strings.ToLower(name), Classes: []string{}, Parameters: map[interface{}]interface{}{}}
+}
+
+// ListTargets Returns the sorted names of all targets in the deployment repository
+func ListTargets() ([]string, error) {
+	return listResourceNames(filepath.Join(util.Context.WorkingDir, targetsPath))
+}
+
+func (target *Target) Create() error {
+	return create(target)
+}
+
+func (target *Target) Read() error {
+	return read(target)
+}
+
+func (target *Target) Update() error {
+	return update(target)
+}
+
+// Delete Deletes the target, targets are not referenced by other resources
+func (target *Target) Delete() error {
+	return deleteResource(target)
+}
+
+// AddClass Adds a class to the target, classes that are already used by the target are ignored
+func (target *Target) AddClass(class string) {
+	if !target.HasClass(class) {
+		target.Classes = append(target.Classes, class)
+	}
+}
+
+// RemoveClass Removes a class from the target, returns false if the target did not use the class
+func (target *Target) RemoveClass(class string) bool {
+	for i, c := range target.Classes {
+		if c == class {
+			target.Classes = append(target.Classes[:i], target.Classes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (target *Target) HasClass(class string) bool {
+	for _, c := range target.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (target *Target) AddStage(stage *Stage) {
+	target.AddClass(stageClassPrefix + stage.Name)
+}
+
+func (target *Target) AddRelease(release *Release) {
+	target.AddClass(releaseClassPrefix + release.Type.String() + "." + release.Name)
+}
+
+func (target *Target) AddAppGroup(group *AppGroup) {
+	target.AddClass(appPrefix + group.Name)
+}
+
+func (target *Target) AddEnvClass(env *EnvClass) {
+	target.AddClass(envClassPrefix + env.Name)
+}
+
+// ResolveVersions Resolves the app versions this target deploys by following its classes chain, see AppList
+func (target *Target) ResolveVersions() (map[string]string, error) {
+	if err := target.Read(); err != nil {
+		return nil, err
+	}
+	versions, err := resolveTargetVersions(target)
+	if err == nil {
+		target.resolved = versions
+	}
+	return versions, err
+}
+
+func (target *Target) versions() map[string]string {
+	if target.resolved == nil {
+		if _, err := target.ResolveVersions(); err != nil {
+			return map[string]string{}
+		}
+	}
+	return target.resolved
+}
+
+func (target *Target) GetVersions(group string, app string) map[string]string {
+	return GetVersions(target, group, app)
+}
+
+func (target *Target) GetArtifacts(group string, app string, artifactType string) (map[string]string, error) {
+	return GetArtifacts(target, group, app, artifactType)
+}
+
+func (target *Target) mapToKapitanFile() *kapitanFile {
+	log.Tracef("Mapping target %s to kapitan file: %+v", target.Name, target)
+	f := newKapitanFile()
+	f.Classes = append(f.Classes, target.Classes...)
+	for key, value := range target.Parameters {
+		f.Parameters[key] = value
+	}
+	if _, exists := f.Parameters["kapitan"]; !exists {
+		f.Parameters["kapitan"] = map[string]interface{}{
+			"vars": map[string]string{"target": target.Name},
+		}
+	}
+	log.Tracef("Mapped target %s to kapitan file, result: %+v", target.Name, f)
+	return f
+}
+
+func (target *Target) mapFromKapitanFile(f *kapitanFile) {
+	log.Tracef("Mapping target %s from kapitan file %+v", target.Name, f)
+	target.Classes = make([]string, 0)
+	target.Classes = append(target.Classes, f.Classes...)
+	target.Parameters = make(map[interface{}]interface{}, 0)
+	for key, value := range f.Parameters {
+		target.Parameters[key] = value
+	}
+	log.Tracef("Mapped target %s from kapitan file, result: %+v", target.Name, target)
+}
+
+func (target *Target) Exists() bool {
+	return exists(target)
+}
+
+func (target *Target) GetFilePath() string {
+	return filepath.Join(util.Context.WorkingDir, targetsPath, target.Name+kapitanFileExt)
+}
+
+func (target *Target) isValid() bool {
+	return strings.TrimSpace(target.Name) != ""
+}
+
+func (target *Target) getResourceType() string {
+	return "target"
+}
+
+func (target *Target) getResourceName() string {
+	return target.Name
+}
@@ -0,0 +1,188 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type TargetSuite struct {
+	suite.Suite
+}
+
+func (suite *TargetSuite) SetupSuite() {
+	TestsSetupWorkingDir(suite.Suite)
+	CreateTestTarget(suite.Suite, "my-target")
+}
+
+func (suite *TargetSuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *TargetSuite) TestGetFilePath() {
+	target := NewTarget("my-target")
+	r := suite.Require()
+	r.Equal(filepath.Join(util.Context.WorkingDir, "inventory/targets/my-target.yml"), target.GetFilePath())
+}
+
+func (suite *TargetSuite) TestReadInvalidStructReturnValidationErr() {
+	target := &Target{}
+	err := target.Read()
+	r := suite.Require()
+	r.NotNil(err)
+	r.Equal(ValidationErr, err)
+}
+
+func (suite *TargetSuite) TestRead() {
+	target := NewTarget("my-target")
+	err := target.Read()
+	r := suite.Require()
+	r.Nil(err)
+	r.Equal("my-target", target.Name)
+	r.Equal([]string{"releases.product.my-release", "stages.alpha", "apps.test"}, target.Classes)
+	r.Contains(target.Parameters, "kapitan")
+	r.Contains(target.Parameters, "app1")
+}
+
+func (suite *TargetSuite) TestCreate() {
+	target := NewTarget("new-target")
+	target.AddRelease(NewRelease("R1", ProductRelease))
+	target.AddStage(NewStage("alpha"))
+	target.AddAppGroup(NewAppGroup("test"))
+	target.AddClass("stages.alpha")
+	err := target.Create()
+	r := suite.Require()
+	r.Nil(err)
+
+	target = NewTarget("new-target")
+	err = target.Read()
+	r.Nil(err)
+	r.Equal([]string{"releases.product.R1", "stages.alpha", "apps.test"}, target.Classes)
+	vars := target.Parameters["kapitan"].(map[interface{}]interface{})["vars"].(map[interface{}]interface{})
+	r.Equal("new-target", vars["target"])
+}
+
+func (suite *TargetSuite) TestNameIsLowercase() {
+	r := suite.Require()
+	target := NewTarget("My-Target")
+	r.Equal("my-target", target.Name)
+	r.True(target.Exists())
+}
+
+func (suite *TargetSuite) TestCreateExistingReturnsErr() {
+	target := NewTarget("my-target")
+	err := target.Create()
+	r := suite.Require()
+	r.NotNil(err)
+	r.Equal(ResourceAlreadyExistsErr, err)
+}
+
+func (suite *TargetSuite) TestUpdate() {
+	target := NewTarget("my-target")
+	r := suite.Require()
+	err := target.Read()
+	r.Nil(err)
+	target.AddClass("env.dev")
+	err = target.Update()
+	r.Nil(err)
+
+	target = NewTarget("my-target")
+	err = target.Read()
+	r.Nil(err)
+	r.Len(target.Classes, 4)
+	r.True(target.HasClass("env.dev"))
+	r.Contains(target.Parameters, "app1")
+}
+
+func (suite *TargetSuite) TestListTargets() {
+	CreateTestTarget(suite.Suite, "another-target")
+	targets, err := ListTargets()
+	r := suite.Require()
+	r.Nil(err)
+	r.Contains(targets, "another-target")
+	r.Contains(targets, "my-target")
+}
+
+func (suite *TargetSuite) TestResolveVersions() {
+	CreateTestRelease(suite.Suite, "my-release", ProductRelease)
+	CreateTestAppGroup(suite.Suite, "test")
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/beta.yml"), `
+parameters:
+  beta:
+    app2: 2.1.0
+    app3: 3.1.0
+`)
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/classes/env/qa.yml"), `
+parameters:
+  app2:
+    version: 2.2.0
+`)
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/targets/resolved.yml"), `
+classes:
+  - releases.product.my-release
+  - stages.beta
+  - env.qa
+parameters:
+  app1:
+    version: 1.1.0
+`)
+	target := NewTarget("resolved")
+	versions, err := target.ResolveVersions()
+	r := suite.Require()
+	r.Nil(err)
+	r.Equal(map[string]string{"app1": "1.1.0", "app2": "2.2.0", "app3": "3.1.0"}, versions)
+
+	target.AddAppGroup(NewAppGroup("test"))
+	versions, err = resolveTargetVersions(target)
+	r.Nil(err)
+	r.Equal(map[string]string{"app1": "1.1.0", "app2": "2.2.0"}, versions)
+}
+
+func (suite *TargetSuite) TestResolveVersionsMissingClassReturnsErr() {
+	target := NewTarget("missing-class")
+	target.AddClass("stages.does-not-exist")
+	err := target.Create()
+	r := suite.Require()
+	r.Nil(err)
+	_, err = target.ResolveVersions()
+	r.NotNil(err)
+	r.Equal(ClassNotFoundErr, err)
+}
+
+func (suite *TargetSuite) TestResolveVersionsFollowsInitAndRelativeClasses() {
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/classes/stages/gamma.yml"), `
+parameters:
+  gamma:
+    app1: 1.0.0
+    app2: 2.0.0
+`)
+	r := suite.Require()
+	r.Nil(os.MkdirAll(filepath.Join(util.Context.WorkingDir, "inventory/classes/env/prod"), 0755))
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/classes/env/prod/init.yml"), `
+classes:
+  - .overrides
+`)
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/classes/env/prod/overrides.yml"), `
+parameters:
+  pinned: 2.0.1
+  app2:
+    version: ${pinned}
+`)
+	filet.File(suite.T(), filepath.Join(util.Context.WorkingDir, "inventory/targets/nested.yml"), `
+classes:
+  - stages.gamma
+  - env.prod
+`)
+	versions, err := NewTarget("nested").ResolveVersions()
+	r.Nil(err)
+	r.Equal(map[string]string{"app1": "1.0.0", "app2": "2.0.1"}, versions)
+}
+
+func TestTargetTestSuite(t *testing.T) {
+	suite.Run(t, new(TargetSuite))
+}
@@ -0,0 +1,125 @@
+package gitops
+
+import (
+	"fmt"
+	"gosh/log"
+	"strings"
+)
+
+// targetVersions Collects the effective app versions of a target from its resolved inventory.
+//
+// Classes are visited in the order kapitan applies them: nested classes first, then the class itself,
+// where later classes override earlier ones and the target's own parameters override all classes.
+// The default version defined in an app file is only used when no stage, release, env class or target
+// parameter sets a version for that app.
+type targetVersions struct {
+	inventory *InventoryTarget
+	versions  map[string]string
+	defaults  map[string]string
+	apps      map[string]bool
+}
+
+// resolveTargetVersions Returns the app versions that will be deployed by the target.
+//
+// When the target includes app or app group classes, only those apps are returned,
+// otherwise all apps that have a version somewhere in the classes chain are returned.
+func resolveTargetVersions(target *Target) (map[string]string, error) {
+	inventory, err := ResolveInventory(target)
+	if err != nil {
+		return nil, err
+	}
+	t := &targetVersions{
+		inventory: inventory,
+		versions:  map[string]string{},
+		defaults:  map[string]string{},
+		apps:      map[string]bool{},
+	}
+	for _, class := range inventory.Classes {
+		if err = t.applyClass(class); err != nil {
+			return nil, log.Errf(err, "Could not resolve versions of class %s in target %s", class, target.Name)
+		}
+	}
+	if err = t.applyOverrides(t.versions, target.Parameters); err != nil {
+		return nil, log.Errf(err, "Could not resolve versions of target %s", target.Name)
+	}
+	result := map[string]string{}
+	if len(t.apps) > 0 {
+		for app := range t.apps {
+			if version, exists := t.versions[app]; exists {
+				result[app] = version
+			} else if version, exists = t.defaults[app]; exists {
+				result[app] = version
+			} else {
+				log.Warnf("No version found for app %s in target %s", app, target.Name)
+			}
+		}
+	} else {
+		for app, version := range t.versions {
+			result[app] = version
+		}
+	}
+	log.Debugf("Resolved versions for target %s: %+v", target.Name, result)
+	return result, nil
+}
+
+func (t *targetVersions) applyClass(class string) error {
+	parameters := t.inventory.classParameters[class]
+	switch {
+	case strings.HasPrefix(class, stageClassPrefix):
+		stage := strings.TrimPrefix(class, stageClassPrefix)
+		if versions, ok := t.inventory.Parameters[stage].(map[interface{}]interface{}); ok {
+			for app, version := range versions {
+				if version != nil {
+					t.versions[fmt.Sprint(app)] = fmt.Sprint(version)
+				}
+			}
+		}
+	case strings.HasPrefix(class, releaseClassPrefix):
+		parts := strings.SplitN(strings.TrimPrefix(class, releaseClassPrefix), ".", 2)
+		if len(parts) != 2 {
+			return log.Errf(InvalidFullReleaseNameErr, "Invalid release class %s", class)
+		}
+		if apps, ok := t.inventory.Parameters[parts[1]].(map[interface{}]interface{}); ok {
+			for app, props := range apps {
+				if version, ok := versionOf(props); ok {
+					t.versions[fmt.Sprint(app)] = version
+				}
+			}
+		}
+	case strings.HasPrefix(class, appPrefix) && strings.Contains(strings.TrimPrefix(class, appPrefix), "."):
+		app := strings.SplitN(strings.TrimPrefix(class, appPrefix), ".", 2)[1]
+		t.apps[app] = true
+		return t.applyOverrides(t.defaults, map[interface{}]interface{}{app: parameters[app]})
+	default:
+		return t.applyOverrides(t.versions, parameters)
+	}
+	return nil
+}
+
+// applyOverrides Applies version overrides of the form 'APP: {version: VERSION}' found in class or target parameters
+func (t *targetVersions) applyOverrides(versions map[string]string, parameters map[interface{}]interface{}) error {
+	for key, value := range parameters {
+		props, ok := value.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		if version, exists := props["version"]; exists && version != nil {
+			resolved, err := t.inventory.interpolate(version)
+			if err != nil {
+				return err
+			}
+			versions[fmt.Sprint(key)] = fmt.Sprint(resolved)
+		}
+	}
+	return nil
+}
+
+// versionOf Returns the version of a release entry of the form '{version: VERSION}'
+func versionOf(props interface{}) (string, bool) {
+	if m, ok := props.(map[interface{}]interface{}); ok {
+		if version, exists := m["version"]; exists && version != nil {
+			return fmt.Sprint(version), true
+		}
+	}
+	return "", false
+}
@@ -51,17 +51,39 @@ parameters:
       version: 3.0.0
 `
 
+const testTargetFileContents = `
+classes:
+  - releases.product.my-release
+  - stages.alpha
+  - apps.test
+parameters:
+  kapitan:
+    vars:
+      target: {{.Name}}
+  app1:
+    version: 1.1.0
+`
+
+const testEnvClassFileContents = `
+parameters:
+  features:
+    debug: true
+  replicas: 1
+`
+
 func init() {
 	appGroupContentsTemplate, _ = template.New("appgroup").Parse(testAppGroupFileContents)
 	stageContentsTemplate, _ = template.New("stage").Parse(testStageFileContents)
 	appContentsTemplate, _ = template.New("app").Parse(testAppFileContents)
 	releaseContentsTemplate, _ = template.New("release").Parse(testReleaseFileContents)
+	targetContentsTemplate, _ = template.New("target").Parse(testTargetFileContents)
+	envClassContentsTemplate, _ = template.New("env").Parse(testEnvClassFileContents)
 	testConfig = &util.GoshConfig{
 		ArtifactRepositories: nil,
 	}
 }
 
-var appContentsTemplate, appGroupContentsTemplate, stageContentsTemplate, releaseContentsTemplate *template.Template
+var appContentsTemplate, appGroupContentsTemplate, stageContentsTemplate, releaseContentsTemplate, targetContentsTemplate, envClassContentsTemplate *template.Template
 var testConfig *util.GoshConfig
 
 func TestsSetupWorkingDir(suite suite.Suite) {
@@ -77,6 +99,10 @@ func TestsSetupWorkingDir(suite suite.Suite) {
 	_ = os.MkdirAll(p, 0755)
 	p = filepath.Join(util.Context.WorkingDir, "inventory/classes/stages")
 	_ = os.MkdirAll(p, 0755)
+	p = filepath.Join(util.Context.WorkingDir, "inventory/classes/env")
+	_ = os.MkdirAll(p, 0755)
+	p = filepath.Join(util.Context.WorkingDir, "inventory/targets")
+	_ = os.MkdirAll(p, 0755)
 }
 
 func CreateTestAppGroup(suite suite.Suite, name string) {
@@ -132,3 +158,29 @@ func CreateTestApp(suite suite.Suite, name string, group string) {
 		log.Fatalln("Could not create test app", err)
 	}
 }
+
+func CreateTestTarget(suite suite.Suite, name string) {
+	if name == "" {
+		name = "my-target"
+	}
+	f := filepath.Join(util.Context.WorkingDir, "inventory/targets/", name+".yml")
+	var tpl bytes.Buffer
+	if err := targetContentsTemplate.Execute(&tpl, &Target{Name: name}); err == nil {
+		filet.File(suite.T(), f, tpl.String())
+	} else {
+		log.Fatalln("Could not create test target", err)
+	}
+}
+
+func CreateTestEnvClass(suite suite.Suite, name string) {
+	if name == "" {
+		name = "dev"
+	}
+	f := filepath.Join(util.Context.WorkingDir, "inventory/classes/env/", name+".yml")
+	var tpl bytes.Buffer
+	if err := envClassContentsTemplate.Execute(&tpl, &EnvClass{Name: name}); err == nil {
+		filet.File(suite.T(), f, tpl.String())
+	} else {
+		log.Fatalln("Could not create test env class", err)
+	}
+}
@@ -0,0 +1,324 @@
+package gitops
+
+import (
+	"fmt"
+	"gosh/log"
+	"gosh/util"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	inventoryPath        = "inventory"
+	goshVersionHolder    = "[gosh:version]"
+	goshRepoHolderPrefix = "[gosh:repo:"
+	unresolvedTemplate   = "<no value>"
+)
+
+var goshPlaceholderRegexp = regexp.MustCompile(`\[gosh:[^\]]*\]`)
+
+// ValidationIssue A problem in the deployment repository, File is relative to the working dir
+type ValidationIssue struct {
+	File    string
+	Message string
+}
+
+func (issue ValidationIssue) String() string {
+	return issue.File + ": " + issue.Message
+}
+
+type inventoryValidator struct {
+	issues []ValidationIssue
+	files  map[string]*kapitanFile
+	apps   map[string]bool
+}
+
+// ValidateInventory Loads the entire inventory and returns all problems found, sorted by file.
+//
+// Checked are: unparsable YAML files, app groups listing classes that point to missing files, app files missing from
+// their group's class list, versions of unknown apps in stages and releases, stages out of sync with their stage
+// release, target classes that do not exist, and app files or templates with unresolved gosh placeholders.
+func ValidateInventory() ([]ValidationIssue, error) {
+	v := &inventoryValidator{
+		issues: make([]ValidationIssue, 0),
+		files:  map[string]*kapitanFile{},
+		apps:   map[string]bool{},
+	}
+	if err := v.parseFiles(); err != nil {
+		return nil, err
+	}
+	if err := v.validateApps(); err != nil {
+		return nil, err
+	}
+	if err := v.validateStages(); err != nil {
+		return nil, err
+	}
+	if err := v.validateReleases(); err != nil {
+		return nil, err
+	}
+	if err := v.validateTargets(); err != nil {
+		return nil, err
+	}
+	if err := v.validateTemplates(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		return v.issues[i].File < v.issues[j].File
+	})
+	log.Debugf("Validated inventory, found %d issue(s)", len(v.issues))
+	return v.issues, nil
+}
+
+func (v *inventoryValidator) addIssue(path string, msg string, args ...interface{}) {
+	if rel, err := filepath.Rel(util.Context.WorkingDir, path); err == nil {
+		path = filepath.ToSlash(rel)
+	}
+	v.issues = append(v.issues, ValidationIssue{File: path, Message: fmt.Sprintf(msg, args...)})
+}
+
+// parseFiles Parses all YAML files of the inventory, files that cannot be parsed are reported and skipped by other checks
+func (v *inventoryValidator) parseFiles() error {
+	root := filepath.Join(util.Context.WorkingDir, inventoryPath)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		v.addIssue(root, "inventory directory does not exist")
+		return nil
+	}
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return log.Errf(err, "Could not read %s", path)
+		}
+		if entry.IsDir() || !(strings.HasSuffix(path, kapitanFileExt) || strings.HasSuffix(path, ".yaml")) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return log.Errf(err, "Could not read %s", path)
+		}
+		if f, err := parseKapitanFile(data); err == nil {
+			v.files[path] = f
+		} else {
+			v.addIssue(path, "invalid YAML: %v", err)
+		}
+		return nil
+	})
+}
+
+func (v *inventoryValidator) validateApps() error {
+	groups, err := ListAppGroups()
+	if err != nil {
+		return err
+	}
+	listed := map[string]bool{}
+	for _, name := range groups {
+		group := NewAppGroup(name)
+		f, parsed := v.files[group.GetFilePath()]
+		if !parsed {
+			continue
+		}
+		for _, class := range f.Classes {
+			listed[class] = true
+			appName := strings.TrimPrefix(class, appPrefix+name+".")
+			if appName == class {
+				v.addIssue(group.GetFilePath(), "class %s is not an app of group %s", class, name)
+			} else if !NewApp(appName, group).Exists() {
+				v.addIssue(group.GetFilePath(), "class %s points to missing file", class)
+			}
+		}
+	}
+	dir := filepath.Join(util.Context.WorkingDir, appGroupPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return log.Errf(err, "Could not list app groups in %s", dir)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		group := NewAppGroup(entry.Name())
+		names, err := listResourceNames(group.GetFolderPath())
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			app := NewApp(name, group)
+			if v.apps[name] {
+				v.addIssue(app.GetFilePath(), "app %s exists in more than one group, app names must be unique", name)
+			}
+			v.apps[name] = true
+			if !group.Exists() {
+				v.addIssue(app.GetFilePath(), "app %s has no group file %s", name, group.Name+kapitanFileExt)
+			} else if !listed[app.className()] {
+				v.addIssue(app.GetFilePath(), "app %s is missing from the class list of group %s", name, group.Name)
+			}
+			if f, parsed := v.files[app.GetFilePath()]; parsed {
+				v.validateAppFile(app, f)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAppFile Checks the app file for template values that were not rendered and unknown gosh placeholders
+func (v *inventoryValidator) validateAppFile(app *App, f *kapitanFile) {
+	props, ok := f.Parameters[app.Name].(map[interface{}]interface{})
+	if !ok {
+		v.addIssue(app.GetFilePath(), "app %s has no parameters named after the app", app.Name)
+		return
+	}
+	walkStrings(props, "", func(key string, value string) {
+		if strings.Contains(value, unresolvedTemplate) {
+			v.addIssue(app.GetFilePath(), "%s has an unresolved template value: %s", key, value)
+		}
+		for _, placeholder := range goshPlaceholderRegexp.FindAllString(value, -1) {
+			if !isKnownPlaceholder(placeholder) {
+				v.addIssue(app.GetFilePath(), "%s has an unresolved gosh placeholder %s", key, placeholder)
+			}
+		}
+	})
+}
+
+func isKnownPlaceholder(placeholder string) bool {
+	if placeholder == goshVersionHolder {
+		return true
+	}
+	if strings.HasPrefix(placeholder, goshRepoHolderPrefix) {
+		_, exists := util.Config.ArtifactRepositories[strings.TrimSuffix(strings.TrimPrefix(placeholder, goshRepoHolderPrefix), "]")]
+		return exists
+	}
+	return false
+}
+
+// walkStrings Calls fn for all string values in a parameters tree, with their dotted key
+func walkStrings(value interface{}, key string, fn func(key string, value string)) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			child := fmt.Sprint(k)
+			if key != "" {
+				child = key + "." + child
+			}
+			walkStrings(item, child, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkStrings(item, fmt.Sprintf("%s[%d]", key, i), fn)
+		}
+	case string:
+		fn(key, v)
+	}
+}
+
+func (v *inventoryValidator) validateVersions(path string, resourceType string, name string, versions map[string]string) {
+	apps := make([]string, 0, len(versions))
+	for app := range versions {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	for _, app := range apps {
+		if !v.apps[app] {
+			v.addIssue(path, "%s %s has a version for unknown app %s", resourceType, name, app)
+		}
+	}
+}
+
+func (v *inventoryValidator) validateStages() error {
+	names, err := ListStages()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		stage := NewStage(name)
+		f, parsed := v.files[stage.GetFilePath()]
+		if !parsed {
+			continue
+		}
+		stage.mapFromKapitanFile(f)
+		v.validateVersions(stage.GetFilePath(), "stage", name, stage.Versions)
+		release := NewRelease(name, StageRelease)
+		rf, parsed := v.files[release.GetFilePath()]
+		if !release.Exists() {
+			v.addIssue(stage.GetFilePath(), "stage %s has no stage release", name)
+			continue
+		} else if !parsed {
+			continue
+		}
+		release.mapFromKapitanFile(rf)
+		for _, diff := range DiffVersions(stage, release, "", "") {
+			v.addIssue(release.GetFilePath(), "stage release %s is out of sync with its stage for app %s: stage has '%s', release has '%s'",
+				name, diff.App, diff.From, diff.To)
+		}
+	}
+	return nil
+}
+
+func (v *inventoryValidator) validateReleases() error {
+	for _, releaseType := range []ReleaseType{StageRelease, ProductRelease, HotFixRelease} {
+		names, err := ListReleases(releaseType)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			release := NewRelease(name, releaseType)
+			f, parsed := v.files[release.GetFilePath()]
+			if !parsed {
+				continue
+			}
+			if releaseType == StageRelease && !NewStage(name).Exists() {
+				v.addIssue(release.GetFilePath(), "stage release %s has no stage", name)
+			}
+			release.mapFromKapitanFile(f)
+			v.validateVersions(release.GetFilePath(), "release", release.FullName(), release.Versions)
+		}
+	}
+	return nil
+}
+
+func (v *inventoryValidator) validateTargets() error {
+	names, err := ListTargets()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		target := NewTarget(name)
+		f, parsed := v.files[target.GetFilePath()]
+		if !parsed {
+			continue
+		}
+		for _, class := range f.Classes {
+			if _, _, err := findClassFile(class); err != nil {
+				v.addIssue(target.GetFilePath(), "class %s does not exist", class)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *inventoryValidator) validateTemplates() error {
+	dir := filepath.Join(util.Context.WorkingDir, appTemplatesPath)
+	names, err := listResourceNames(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name+kapitanFileExt)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return log.Errf(err, "Could not read template %s", path)
+		}
+		if _, err = template.New(name).Parse(string(data)); err != nil {
+			v.addIssue(path, "invalid app template: %v", err)
+			continue
+		}
+		for _, placeholder := range goshPlaceholderRegexp.FindAllString(string(data), -1) {
+			if !isKnownPlaceholder(placeholder) {
+				v.addIssue(path, "unresolved gosh placeholder %s", placeholder)
+			}
+		}
+	}
+	return nil
+}
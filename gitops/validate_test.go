@@ -0,0 +1,99 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type ValidateSuite struct {
+	suite.Suite
+}
+
+func (suite *ValidateSuite) SetupTest() {
+	TestsSetupWorkingDir(suite.Suite)
+	util.Config.ArtifactRepositories = map[string]map[string]string{"maven": {"default": "https://maven"}}
+	CreateTestAppGroup(suite.Suite, "test")
+	CreateTestApp(suite.Suite, "app1", "test")
+	CreateTestApp(suite.Suite, "app2", "test")
+	suite.createFile("inventory/classes/apps/other/app3.yml", "parameters:\n  app3:\n    app_name: app3\n")
+	suite.createFile("inventory/classes/apps/other.yml", "classes:\n  - apps.other.app3\n")
+	CreateTestStage(suite.Suite, "alpha")
+	suite.createFile("inventory/classes/releases/stage/alpha.yml", `
+parameters:
+  alpha:
+    app1:
+      version: 1.0.0
+    app2:
+      version: 2.0.0
+    app3:
+      version: 3.0.0
+`)
+	CreateTestRelease(suite.Suite, "my-release", ProductRelease)
+	CreateTestTarget(suite.Suite, "my-target")
+}
+
+func (suite *ValidateSuite) TearDownTest() {
+	util.Config.ArtifactRepositories = nil
+	filet.CleanUp(suite.T())
+}
+
+func (suite *ValidateSuite) createFile(path string, contents string) {
+	f := filepath.Join(util.Context.WorkingDir, path)
+	_ = os.MkdirAll(filepath.Dir(f), 0755)
+	suite.Require().Nil(os.WriteFile(f, []byte(contents), 0644))
+}
+
+func (suite *ValidateSuite) TestValidInventory() {
+	issues, err := ValidateInventory()
+	r := suite.Require()
+	r.Nil(err)
+	r.Empty(issues)
+}
+
+func (suite *ValidateSuite) TestValidateInventoryIssues() {
+	suite.createFile("inventory/classes/apps/test.yml", "classes:\n  - apps.test.app1\n  - apps.test.missing\n")
+	suite.createFile("inventory/classes/stages/beta.yml", "parameters:\n  beta:\n    app1: 1.0.0\n    typo: 1.0.0\n")
+	suite.createFile("inventory/classes/releases/stage/beta.yml", "parameters:\n  beta:\n    app1:\n      version: 0.9.0\n")
+	suite.createFile("inventory/classes/broken.yml", "parameters: [")
+	suite.createFile("inventory/classes/apps/other/app3.yml", `
+parameters:
+  app3:
+    groupId: <no value>
+    artifacts:
+      maven: "[gosh:repo:maven]/app3/[gosh:version]"
+      docker: "[gosh:repo:docker]/app3:[gosh:version]"
+`)
+	suite.createFile("inventory/targets/typo.yml", "classes:\n  - stages.alhpa\n")
+	suite.createFile(".gosh/templates/custom.yml", "parameters:\n  {{.Name}:\n")
+
+	issues, err := ValidateInventory()
+	r := suite.Require()
+	r.Nil(err)
+	messages := make([]string, 0)
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	sort.Strings(messages)
+	r.True(strings.HasPrefix(messages[0], ".gosh/templates/custom.yml: invalid app template:"), messages[0])
+	r.Equal([]string{
+		"inventory/classes/apps/other/app3.yml: artifacts.docker has an unresolved gosh placeholder [gosh:repo:docker]",
+		"inventory/classes/apps/other/app3.yml: groupId has an unresolved template value: <no value>",
+		"inventory/classes/apps/test.yml: class apps.test.missing points to missing file",
+		"inventory/classes/apps/test/app2.yml: app app2 is missing from the class list of group test",
+		"inventory/classes/broken.yml: invalid YAML: yaml: line 1: did not find expected node content",
+		"inventory/classes/releases/stage/beta.yml: stage release beta is out of sync with its stage for app app1: stage has '1.0.0', release has '0.9.0'",
+		"inventory/classes/releases/stage/beta.yml: stage release beta is out of sync with its stage for app typo: stage has '1.0.0', release has ''",
+		"inventory/classes/stages/beta.yml: stage beta has a version for unknown app typo",
+		"inventory/targets/typo.yml: class stages.alhpa does not exist",
+	}, messages[1:])
+}
+
+func TestValidateTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidateSuite))
+}
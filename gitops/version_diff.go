@@ -0,0 +1,120 @@
+package gitops
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type VersionChange int
+
+const (
+	AddedVersion VersionChange = iota + 1
+	RemovedVersion
+	UpgradedVersion
+	DowngradedVersion
+	ChangedVersion
+)
+
+func (c VersionChange) String() string {
+	return [...]string{"added", "removed", "upgraded", "downgraded", "changed"}[c-1]
+}
+
+// VersionDiff The difference of the version of a single app between two AppList instances
+type VersionDiff struct {
+	App    string
+	Change VersionChange
+	From   string
+	To     string
+}
+
+var versionPartsRegexp = regexp.MustCompile(`\d+|[^\d.\-+_]+`)
+
+// DiffVersions Compares the versions of two AppList instances, apps with the same version in both lists are not included.
+//
+// Group and app filters are applied the same way as GetVersions, the result is sorted by app name.
+// Versions that cannot be ordered, e.g. 'latest' and '1.0.0', are reported as changed.
+func DiffVersions(from AppList, to AppList, group string, app string) []VersionDiff {
+	fromVersions := from.GetVersions(group, app)
+	toVersions := to.GetVersions(group, app)
+	result := make([]VersionDiff, 0)
+	for name, fromVersion := range fromVersions {
+		if toVersion, exists := toVersions[name]; exists {
+			if fromVersion == toVersion {
+				continue
+			}
+			change := ChangedVersion
+			if cmp, ok := CompareVersions(fromVersion, toVersion); ok {
+				if cmp < 0 {
+					change = UpgradedVersion
+				} else if cmp > 0 {
+					change = DowngradedVersion
+				}
+			}
+			result = append(result, VersionDiff{App: name, Change: change, From: fromVersion, To: toVersion})
+		} else {
+			result = append(result, VersionDiff{App: name, Change: RemovedVersion, From: fromVersion})
+		}
+	}
+	for name, toVersion := range toVersions {
+		if _, exists := fromVersions[name]; !exists {
+			result = append(result, VersionDiff{App: name, Change: AddedVersion, To: toVersion})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].App < result[j].App
+	})
+	return result
+}
+
+// CompareVersions Compares two version strings part by part, numeric parts are compared as numbers.
+//
+// Returns -1, 0 or 1 and true when the versions can be ordered, versions can only be ordered if both start with a
+// number (optionally prefixed with 'v')
+func CompareVersions(a string, b string) (int, bool) {
+	a = strings.TrimPrefix(strings.TrimSpace(a), "v")
+	b = strings.TrimPrefix(strings.TrimSpace(b), "v")
+	if a == "" || b == "" || !isDigit(a[0]) || !isDigit(b[0]) {
+		return 0, false
+	}
+	aParts := versionPartsRegexp.FindAllString(a, -1)
+	bParts := versionPartsRegexp.FindAllString(b, -1)
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if cmp := compareVersionPart(aParts[i], bParts[i]); cmp != 0 {
+			return cmp, true
+		}
+	}
+	//a longer version is greater, unless the extra part is a pre-release suffix like 'rc1'
+	switch {
+	case len(aParts) < len(bParts):
+		return -compareVersionPart(bParts[len(aParts)], "0"), true
+	case len(aParts) > len(bParts):
+		return compareVersionPart(aParts[len(bParts)], "0"), true
+	}
+	return 0, true
+}
+
+func compareVersionPart(a string, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum < bNum {
+			return -1
+		} else if aNum > bNum {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		//numbers are ordered after text, so 1.0.0 > 1.0.0-rc
+		return 1
+	case bErr == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,65 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type VersionDiffSuite struct {
+	suite.Suite
+}
+
+func (suite *VersionDiffSuite) SetupSuite() {
+	TestsSetupWorkingDir(suite.Suite)
+}
+
+func (suite *VersionDiffSuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *VersionDiffSuite) TestDiffVersions() {
+	from := NewStage("from")
+	from.Versions = map[string]string{"app1": "1.0.0", "app2": "2.0.0", "app3": "1.9.0", "app4": "latest", "app5": "5.0.0"}
+	to := NewStage("to")
+	to.Versions = map[string]string{"app1": "1.0.0", "app2": "1.10.0", "app3": "1.10.0", "app4": "4.0.0", "app6": "6.0.0"}
+	diff := DiffVersions(from, to, "", "")
+	r := suite.Require()
+	r.Equal([]VersionDiff{
+		{App: "app2", Change: DowngradedVersion, From: "2.0.0", To: "1.10.0"},
+		{App: "app3", Change: UpgradedVersion, From: "1.9.0", To: "1.10.0"},
+		{App: "app4", Change: ChangedVersion, From: "latest", To: "4.0.0"},
+		{App: "app5", Change: RemovedVersion, From: "5.0.0"},
+		{App: "app6", Change: AddedVersion, To: "6.0.0"},
+	}, diff)
+
+	diff = DiffVersions(from, to, "", "app3")
+	r.Equal([]VersionDiff{{App: "app3", Change: UpgradedVersion, From: "1.9.0", To: "1.10.0"}}, diff)
+}
+
+func (suite *VersionDiffSuite) TestCompareVersions() {
+	r := suite.Require()
+	cases := []struct {
+		a, b string
+		cmp  int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"v2.0.0", "1.9.9", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+		{"2021.10.1", "2021.9.30", 1},
+		{"1.0", "1.0.1", -1},
+	}
+	for _, c := range cases {
+		cmp, ok := CompareVersions(c.a, c.b)
+		r.True(ok, "%s <> %s", c.a, c.b)
+		r.Equal(c.cmp, cmp, "%s <> %s", c.a, c.b)
+	}
+	_, ok := CompareVersions("latest", "1.0.0")
+	r.False(ok)
+}
+
+func TestVersionDiffTestSuite(t *testing.T) {
+	suite.Run(t, new(VersionDiffSuite))
+}
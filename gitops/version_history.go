@@ -0,0 +1,104 @@
+package gitops
+
+import (
+	"errors"
+	"gosh/log"
+	"time"
+)
+
+var (
+	NoPreviousVersionErr = errors.New("no previous version found in the history")
+)
+
+// FileRevision A version of a resource file in the git history, Contents is nil when the file did not exist
+type FileRevision struct {
+	Commit   string
+	Author   string
+	When     time.Time
+	Contents []byte
+}
+
+// VersionHistoryEntry A change of the version of an app, From is empty when the app was added and To when it was removed
+type VersionHistoryEntry struct {
+	Commit string
+	Author string
+	When   time.Time
+	From   string
+	To     string
+}
+
+type versionedResource interface {
+	Resource
+	AppList
+}
+
+// VersionHistory Returns the changes of the version of an app in the stage, newest first.
+//
+// The revisions are the versions of the stage file, newest first, as returned by the deployment repository.
+func (stage *Stage) VersionHistory(app string, revisions []FileRevision) ([]VersionHistoryEntry, error) {
+	return versionHistory(NewStage(stage.Name), app, revisions)
+}
+
+// VersionHistory Returns the changes of the version of an app in the release, newest first.
+//
+// The revisions are the versions of the release file, newest first, as returned by the deployment repository.
+func (release *Release) VersionHistory(app string, revisions []FileRevision) ([]VersionHistoryEntry, error) {
+	return versionHistory(NewRelease(release.Name, release.Type), app, revisions)
+}
+
+// PreviousVersion Returns the version an app had in the stage before its last 'steps' version changes.
+//
+// The revisions are the versions of the stage file, newest first, as returned by the deployment repository.
+func (stage *Stage) PreviousVersion(app string, revisions []FileRevision, steps int) (string, error) {
+	if steps < 1 {
+		return "", log.Errf(NoPreviousVersionErr, "Steps must be at least 1, got %d", steps)
+	}
+	history, err := stage.VersionHistory(app, revisions)
+	if err != nil {
+		return "", err
+	}
+	if steps > len(history) || history[steps-1].From == "" {
+		return "", log.Errf(NoPreviousVersionErr, "App %s has no version %d change(s) back in stage %s", app, steps, stage.Name)
+	}
+	return history[steps-1].From, nil
+}
+
+// VersionAt Returns the version an app had in a revision of the stage file
+func (stage *Stage) VersionAt(app string, revision FileRevision) (string, error) {
+	history, err := stage.VersionHistory(app, []FileRevision{revision})
+	if err != nil {
+		return "", err
+	}
+	if len(history) == 0 {
+		return "", log.Errf(NoPreviousVersionErr, "App %s has no version in stage %s in commit %s", app, stage.Name, revision.Commit)
+	}
+	return history[0].To, nil
+}
+
+func versionHistory(resource versionedResource, app string, revisions []FileRevision) ([]VersionHistoryEntry, error) {
+	history := make([]VersionHistoryEntry, 0)
+	previous := ""
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+		version := ""
+		if revision.Contents != nil {
+			f, err := parseKapitanFile(revision.Contents)
+			if err != nil {
+				return nil, log.Errf(err, "Could not parse %s '%s' in commit %s", resource.getResourceType(), resource.getResourceName(), revision.Commit)
+			}
+			resource.mapFromKapitanFile(f)
+			version = resource.versions()[app]
+		}
+		if version != previous {
+			history = append([]VersionHistoryEntry{{
+				Commit: revision.Commit,
+				Author: revision.Author,
+				When:   revision.When,
+				From:   previous,
+				To:     version,
+			}}, history...)
+			previous = version
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,91 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type VersionHistorySuite struct {
+	suite.Suite
+}
+
+func (suite *VersionHistorySuite) SetupSuite() {
+	TestsSetupWorkingDir(suite.Suite)
+}
+
+func (suite *VersionHistorySuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *VersionHistorySuite) TestStageVersionHistory() {
+	revisions := []FileRevision{
+		{Commit: "5", Contents: nil},
+		{Commit: "4", Contents: []byte("parameters:\n  alpha:\n    app1: 1.2.0\n")},
+		{Commit: "3", Contents: []byte("parameters:\n  alpha:\n    app1: 1.1.0\n    app2: 2.0.0\n")},
+		{Commit: "2", Contents: []byte("parameters:\n  alpha:\n    app1: 1.1.0\n")},
+		{Commit: "1", Contents: []byte("parameters:\n  alpha:\n    app2: 1.0.0\n")},
+	}
+	history, err := NewStage("alpha").VersionHistory("app1", revisions)
+	r := suite.Require()
+	r.Nil(err)
+	r.Equal([]VersionHistoryEntry{
+		{Commit: "5", From: "1.2.0"},
+		{Commit: "4", From: "1.1.0", To: "1.2.0"},
+		{Commit: "2", To: "1.1.0"},
+	}, history)
+}
+
+func (suite *VersionHistorySuite) TestReleaseVersionHistory() {
+	revisions := []FileRevision{
+		{Commit: "2", Author: "dev", Contents: []byte("parameters:\n  R1:\n    app1:\n      version: 1.1.0\n  release_states:\n    R1: final\n")},
+		{Commit: "1", Contents: []byte("parameters:\n  R1:\n    app1:\n      version: 1.0.0\n")},
+	}
+	history, err := NewRelease("R1", ProductRelease).VersionHistory("app1", revisions)
+	r := suite.Require()
+	r.Nil(err)
+	r.Equal([]VersionHistoryEntry{
+		{Commit: "2", Author: "dev", From: "1.0.0", To: "1.1.0"},
+		{Commit: "1", To: "1.0.0"},
+	}, history)
+}
+
+func (suite *VersionHistorySuite) TestVersionHistoryInvalidFileReturnsErr() {
+	revisions := []FileRevision{{Commit: "1", Contents: []byte("parameters: [")}}
+	_, err := NewStage("alpha").VersionHistory("app1", revisions)
+	suite.Require().NotNil(err)
+}
+
+func (suite *VersionHistorySuite) TestPreviousVersion() {
+	revisions := []FileRevision{
+		{Commit: "3", Contents: []byte("parameters:\n  alpha:\n    app1: 1.2.0\n")},
+		{Commit: "2", Contents: []byte("parameters:\n  alpha:\n    app1: 1.1.0\n")},
+		{Commit: "1", Contents: []byte("parameters:\n  alpha:\n    app1: 1.0.0\n")},
+	}
+	stage := NewStage("alpha")
+	r := suite.Require()
+	version, err := stage.PreviousVersion("app1", revisions, 1)
+	r.Nil(err)
+	r.Equal("1.1.0", version)
+	version, err = stage.PreviousVersion("app1", revisions, 2)
+	r.Nil(err)
+	r.Equal("1.0.0", version)
+	_, err = stage.PreviousVersion("app1", revisions, 3)
+	r.Equal(NoPreviousVersionErr, err)
+	_, err = stage.PreviousVersion("app1", revisions, 0)
+	r.Equal(NoPreviousVersionErr, err)
+}
+
+func (suite *VersionHistorySuite) TestVersionAt() {
+	stage := NewStage("alpha")
+	r := suite.Require()
+	version, err := stage.VersionAt("app1", FileRevision{Commit: "1", Contents: []byte("parameters:\n  alpha:\n    app1: 1.0.0\n")})
+	r.Nil(err)
+	r.Equal("1.0.0", version)
+	_, err = stage.VersionAt("app2", FileRevision{Commit: "1", Contents: []byte("parameters:\n  alpha:\n    app1: 1.0.0\n")})
+	r.Equal(NoPreviousVersionErr, err)
+}
+
+func TestVersionHistoryTestSuite(t *testing.T) {
+	suite.Run(t, new(VersionHistorySuite))
+}
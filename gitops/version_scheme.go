@@ -0,0 +1,129 @@
+package gitops
+
+import (
+	"errors"
+	"gosh/log"
+	"gosh/util"
+	"regexp"
+	"strings"
+)
+
+type VersionScheme int
+
+const (
+	SemVerScheme VersionScheme = iota + 1
+	CalendarScheme
+	FreeFormScheme
+)
+
+const versionSchemeProperty = "version_scheme"
+
+var (
+	UnsupportedVersionSchemeErr = errors.New("unsupported version scheme, use semver, calendar or free-form")
+	InvalidCalendarVersionErr   = errors.New("invalid calendar version, expected a version starting with a year like 2021.10.1 or 2021.R2")
+	InvalidVersionErr           = errors.New("invalid version for the version scheme of the app")
+	VersionDowngradeErr         = errors.New("version is lower than the current version")
+	calendarVersionRegexp       = regexp.MustCompile(`^(\d{2}|\d{4})([.\-_][0-9A-Za-z.\-_+]+)?$`)
+)
+
+func (s VersionScheme) String() string {
+	return [...]string{"semver", "calendar", "free-form"}[s-1]
+}
+
+func NewVersionScheme(value string) (VersionScheme, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "semver":
+		return SemVerScheme, nil
+	case "calendar":
+		return CalendarScheme, nil
+	case "free-form":
+		return FreeFormScheme, nil
+	}
+	return 0, UnsupportedVersionSchemeErr
+}
+
+// DefaultVersionScheme Returns the configured version scheme for apps that do not set one, semver if none is configured
+func DefaultVersionScheme() VersionScheme {
+	if scheme, err := NewVersionScheme(util.Config.Versions.Scheme); err == nil {
+		return scheme
+	} else if util.Config.Versions.Scheme != "" {
+		log.Warnf("Unsupported default version scheme '%s', using semver", util.Config.Versions.Scheme)
+	}
+	return SemVerScheme
+}
+
+// Validate Returns an error if the version does not follow the scheme, free-form versions only have to be non-empty
+func (s VersionScheme) Validate(version string) error {
+	if strings.TrimSpace(version) == "" {
+		return InvalidVersionErr
+	}
+	switch s {
+	case SemVerScheme:
+		_, err := ParseSemVer(version)
+		return err
+	case CalendarScheme:
+		if !calendarVersionRegexp.MatchString(strings.TrimSpace(version)) {
+			return InvalidCalendarVersionErr
+		}
+	}
+	return nil
+}
+
+// Compare Returns -1, 0 or 1 and true when both versions follow the scheme and can be ordered, free-form versions are never ordered.
+//
+// Semantic versions are ordered by semver precedence, see SemVer.Compare, calendar versions with CompareVersions
+func (s VersionScheme) Compare(a string, b string) (int, bool) {
+	if s == FreeFormScheme || s.Validate(a) != nil || s.Validate(b) != nil {
+		return 0, false
+	}
+	if s == SemVerScheme {
+		aVer, _ := ParseSemVer(a)
+		bVer, _ := ParseSemVer(b)
+		return aVer.Compare(bVer), true
+	}
+	return CompareVersions(a, b)
+}
+
+// VersionScheme Returns the version scheme of the app, set by the 'version_scheme' property, or the default scheme
+func (app *App) VersionScheme() (VersionScheme, error) {
+	if err := app.Read(); err != nil {
+		return 0, err
+	}
+	if value, exists := app.Properties[versionSchemeProperty]; exists {
+		if scheme, err := NewVersionScheme(value); err == nil {
+			return scheme, nil
+		} else {
+			return 0, log.Errf(err, "App %s has unsupported version scheme '%s'", app.Name, value)
+		}
+	}
+	return DefaultVersionScheme(), nil
+}
+
+// CheckVersionUpdate Checks if the version of an app in the list can be updated, according to the version scheme of the app.
+//
+// Returns VersionDowngradeErr when the version is lower than the current version, unless allowDowngrade is set. Versions
+// that do not follow the scheme, e.g. 'latest' or a build id, cannot be compared and allow any update.
+func CheckVersionUpdate(list AppList, appName string, version string, allowDowngrade bool) error {
+	app, err := FindApp(appName)
+	if err != nil {
+		return log.Errf(ResourceDoesNotExistErr, "the app with name %s does not exist", appName)
+	}
+	scheme, err := app.VersionScheme()
+	if err != nil {
+		return err
+	}
+	if allowDowngrade {
+		return nil
+	}
+	current, exists := list.versions()[app.Name]
+	if !exists {
+		return nil
+	}
+	if c, ok := scheme.Compare(version, current); ok && c < 0 {
+		return log.Errf(VersionDowngradeErr, "Version %s of app %s is lower than the current version %s of %s, use allow downgrade to force it",
+			version, appName, current, list.getResourceName())
+	} else if !ok {
+		log.Debugf("Versions %s and %s of app %s cannot be compared using scheme %s", version, current, appName, scheme)
+	}
+	return nil
+}
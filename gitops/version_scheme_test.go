@@ -0,0 +1,130 @@
+package gitops
+
+import (
+	"github.com/Flaque/filet"
+	"github.com/stretchr/testify/suite"
+	"gosh/util"
+	"testing"
+)
+
+type VersionSchemeSuite struct {
+	suite.Suite
+}
+
+func (suite *VersionSchemeSuite) SetupSuite() {
+	TestsSetupWorkingDir(suite.Suite)
+	CreateTestAppGroup(suite.Suite, "test")
+	CreateTestApp(suite.Suite, "semver-app", "test")
+	CreateTestApp(suite.Suite, "calendar-app", "test")
+	CreateTestApp(suite.Suite, "free-app", "test")
+	suite.setScheme("calendar-app", "calendar")
+	suite.setScheme("free-app", "free-form")
+}
+
+func (suite *VersionSchemeSuite) TearDownSuite() {
+	filet.CleanUp(suite.T())
+}
+
+func (suite *VersionSchemeSuite) setScheme(appName string, scheme string) {
+	app := NewApp(appName, NewAppGroup("test"))
+	r := suite.Require()
+	r.Nil(app.Read())
+	app.Properties[versionSchemeProperty] = scheme
+	r.Nil(app.Update())
+}
+
+func (suite *VersionSchemeSuite) TestParseSemVer() {
+	r := suite.Require()
+	v, err := ParseSemVer("v1.2.3-rc.1+build.5")
+	r.Nil(err)
+	r.Equal(&SemVer{Major: 1, Minor: 2, Patch: 3, PreRelease: []string{"rc", "1"}, Build: "build.5"}, v)
+	r.Equal("1.2.3-rc.1+build.5", v.String())
+	for _, invalid := range []string{"1.2", "01.2.3", "1.2.3-", "latest", "1.2.3.4"} {
+		_, err = ParseSemVer(invalid)
+		r.Equal(InvalidSemVerErr, err, invalid)
+	}
+}
+
+func (suite *VersionSchemeSuite) TestSemVerCompare() {
+	r := suite.Require()
+	ordered := []string{"1.0.0-0.3.7", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.2.0", "1.10.0", "v2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		c, ok := SemVerScheme.Compare(ordered[i-1], ordered[i])
+		r.True(ok)
+		r.Equal(-1, c, "%s < %s", ordered[i-1], ordered[i])
+		c, _ = SemVerScheme.Compare(ordered[i], ordered[i-1])
+		r.Equal(1, c, "%s > %s", ordered[i], ordered[i-1])
+	}
+	c, ok := SemVerScheme.Compare("1.0.0+build.1", "1.0.0+build.2")
+	r.True(ok)
+	r.Equal(0, c)
+	_, ok = SemVerScheme.Compare("1.0", "1.0.0")
+	r.False(ok)
+}
+
+func (suite *VersionSchemeSuite) TestNewVersionScheme() {
+	r := suite.Require()
+	scheme, err := NewVersionScheme("Calendar")
+	r.Nil(err)
+	r.Equal(CalendarScheme, scheme)
+	r.Equal("free-form", FreeFormScheme.String())
+	_, err = NewVersionScheme("roman")
+	r.Equal(UnsupportedVersionSchemeErr, err)
+}
+
+func (suite *VersionSchemeSuite) TestVersionSchemeCompare() {
+	r := suite.Require()
+	c, ok := CalendarScheme.Compare("2021.10.1", "2021.9.2")
+	r.True(ok)
+	r.Equal(1, c)
+	r.NotNil(CalendarScheme.Validate("latest"))
+	_, ok = FreeFormScheme.Compare("2.0.0", "1.0.0")
+	r.False(ok)
+	r.Nil(FreeFormScheme.Validate("latest"))
+}
+
+func (suite *VersionSchemeSuite) TestAppVersionScheme() {
+	r := suite.Require()
+	app, _ := FindApp("calendar-app")
+	scheme, err := app.VersionScheme()
+	r.Nil(err)
+	r.Equal(CalendarScheme, scheme)
+
+	app, _ = FindApp("semver-app")
+	scheme, err = app.VersionScheme()
+	r.Nil(err)
+	r.Equal(SemVerScheme, scheme)
+
+	util.Config.Versions.Scheme = "free-form"
+	defer func() { util.Config.Versions.Scheme = "" }()
+	scheme, err = app.VersionScheme()
+	r.Nil(err)
+	r.Equal(FreeFormScheme, scheme)
+}
+
+func (suite *VersionSchemeSuite) TestCheckVersionUpdate() {
+	stage := NewStage("check")
+	stage.Versions = map[string]string{"semver-app": "1.2.0", "calendar-app": "2021.10.1", "free-app": "latest"}
+	r := suite.Require()
+	r.Nil(CheckVersionUpdate(stage, "semver-app", "1.10.0", false))
+	r.Equal(VersionDowngradeErr, CheckVersionUpdate(stage, "semver-app", "1.2.0-rc.1", false))
+	r.Nil(CheckVersionUpdate(stage, "semver-app", "1.1.0", true))
+	r.Nil(CheckVersionUpdate(stage, "semver-app", "latest", false))
+	r.Nil(CheckVersionUpdate(stage, "semver-app", "1.0", false))
+	r.Equal(VersionDowngradeErr, CheckVersionUpdate(stage, "calendar-app", "2021.9.30", false))
+	r.Nil(CheckVersionUpdate(stage, "calendar-app", "2022.1.0", false))
+	r.Nil(CheckVersionUpdate(stage, "free-app", "0.1", false))
+	r.Equal(ResourceDoesNotExistErr, CheckVersionUpdate(stage, "unknown-app", "1.0.0", false))
+
+	stage.Versions["semver-app"] = "latest"
+	r.Nil(CheckVersionUpdate(stage, "semver-app", "0.1.0", false))
+
+	stage.Versions["semver-app"] = "2.0.0-alpha.1"
+	r.Nil(CheckVersionUpdate(stage, "semver-app", "2.0.0-alpha.beta", false))
+	r.Equal(VersionDowngradeErr, CheckVersionUpdate(stage, "semver-app", "2.0.0-0.3.7", false))
+}
+
+func TestVersionSchemeSuite(t *testing.T) {
+	suite.Run(t, new(VersionSchemeSuite))
+}
@@ -0,0 +1,97 @@
+package gitops
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"gosh/log"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	UnsupportedVersionsFormatErr = errors.New("unsupported versions file format, use yaml, properties or json")
+	InvalidVersionsFileErr       = errors.New("invalid versions file, expected a flat list of app names and versions")
+)
+
+// VersionsFormatFromFile Returns the versions file format based on the extension of the file, yaml when it is unknown
+func VersionsFormatFromFile(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".properties":
+		return "properties"
+	case ".json":
+		return "json"
+	}
+	return "yaml"
+}
+
+// ParseVersions Parses a flat list of app names and versions in yaml, properties or json format
+func ParseVersions(data []byte, format string) (map[string]string, error) {
+	switch strings.ToLower(format) {
+	case "", "yaml", "yml":
+		// decoding into strings keeps versions like 1.10 as written instead of parsing them as numbers
+		values := map[string]string{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, log.Errf(InvalidVersionsFileErr, "Could not parse yaml versions: %v", err)
+		}
+		return checkVersions(values)
+	case "json":
+		values := map[string]interface{}{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, log.Errf(InvalidVersionsFileErr, "Could not parse json versions: %v", err)
+		}
+		return toVersions(values)
+	case "properties":
+		return parseVersionProperties(data)
+	}
+	return nil, UnsupportedVersionsFormatErr
+}
+
+func toVersions(values map[string]interface{}) (map[string]string, error) {
+	versions := make(map[string]string, len(values))
+	for app, value := range values {
+		switch value.(type) {
+		case string, json.Number:
+			versions[app] = fmt.Sprint(value)
+		default:
+			return nil, log.Errf(InvalidVersionsFileErr, "Version of app %s is not a plain value", app)
+		}
+	}
+	return checkVersions(versions)
+}
+
+func checkVersions(versions map[string]string) (map[string]string, error) {
+	for app, version := range versions {
+		if strings.TrimSpace(version) == "" {
+			return nil, log.Errf(InvalidVersionsFileErr, "Version of app %s is empty", app)
+		}
+		versions[app] = strings.TrimSpace(version)
+	}
+	return versions, nil
+}
+
+// parseVersionProperties Parses app=version lines, 'app: version' is also accepted and lines starting with # or ! are comments
+func parseVersionProperties(data []byte) (map[string]string, error) {
+	versions := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "!") {
+			continue
+		}
+		i := strings.IndexAny(text, "=:")
+		if i < 1 || strings.TrimSpace(text[i+1:]) == "" {
+			return nil, log.Errf(InvalidVersionsFileErr, "Invalid version on line %d: %s", line, text)
+		}
+		versions[strings.TrimSpace(text[:i])] = strings.TrimSpace(text[i+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, log.Errf(InvalidVersionsFileErr, "Could not read properties versions: %v", err)
+	}
+	return versions, nil
+}
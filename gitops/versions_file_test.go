@@ -0,0 +1,50 @@
+package gitops
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type VersionsFileSuite struct {
+	suite.Suite
+}
+
+func (suite *VersionsFileSuite) TestParseVersions() {
+	expected := map[string]string{"app1": "1.10", "app2": "2.0.0-rc.1"}
+	r := suite.Require()
+	versions, err := ParseVersions([]byte("app1: 1.10\napp2: \"2.0.0-rc.1\"\n"), "yaml")
+	r.Nil(err)
+	r.Equal(expected, versions)
+
+	versions, err = ParseVersions([]byte("# platform release\napp1=1.10\n\napp2 = 2.0.0-rc.1\n"), "properties")
+	r.Nil(err)
+	r.Equal(expected, versions)
+
+	versions, err = ParseVersions([]byte(`{"app1": 1.10, "app2": "2.0.0-rc.1"}`), "json")
+	r.Nil(err)
+	r.Equal(expected, versions)
+}
+
+func (suite *VersionsFileSuite) TestParseInvalidVersions() {
+	r := suite.Require()
+	_, err := ParseVersions([]byte("app1:\n  nested: 1.0.0\n"), "yaml")
+	r.Equal(InvalidVersionsFileErr, err)
+	_, err = ParseVersions([]byte("app1\n"), "properties")
+	r.Equal(InvalidVersionsFileErr, err)
+	_, err = ParseVersions([]byte(`{"app1": ""}`), "json")
+	r.Equal(InvalidVersionsFileErr, err)
+	_, err = ParseVersions([]byte("app1=1.0.0"), "toml")
+	r.Equal(UnsupportedVersionsFormatErr, err)
+}
+
+func (suite *VersionsFileSuite) TestVersionsFormatFromFile() {
+	r := suite.Require()
+	r.Equal("json", VersionsFormatFromFile("release/versions.JSON"))
+	r.Equal("properties", VersionsFormatFromFile("versions.properties"))
+	r.Equal("yaml", VersionsFormatFromFile("versions.yml"))
+	r.Equal("yaml", VersionsFormatFromFile("-"))
+}
+
+func TestVersionsFileSuite(t *testing.T) {
+	suite.Run(t, new(VersionsFileSuite))
+}
@@ -44,8 +44,42 @@ func Render(format string, list map[string]string, keySuffix string) (string, er
 	return "", UnsupportedOutputFormatErr
 }
 
+// RenderDiff Renders a list of differences between two lists, e.g. the version diff of two stages
+func RenderDiff(format string, diff []DiffEntry) (string, error) {
+	if format == "" {
+		format = util.Config.Output.DefaultFormat
+		if format == "" {
+			format = DefaultOutputFormat
+		}
+	}
+	if format, exists := outputFormats[format]; exists {
+		return format.RenderDiff(diff)
+	}
+	return "", UnsupportedOutputFormatErr
+}
+
 type OutputFormat interface {
 	Render(list map[string]string, keySuffix string) (string, error)
+	RenderDiff(diff []DiffEntry) (string, error)
+}
+
+// DiffEntry A single difference between two lists, From is empty for added entries and To for removed ones
+type DiffEntry struct {
+	Name   string
+	Change string
+	From   string
+	To     string
+}
+
+func (e DiffEntry) toMap() yaml.MapSlice {
+	m := yaml.MapSlice{{Key: "change", Value: e.Change}}
+	if e.From != "" {
+		m = append(m, yaml.MapItem{Key: "from", Value: e.From})
+	}
+	if e.To != "" {
+		m = append(m, yaml.MapItem{Key: "to", Value: e.To})
+	}
+	return m
 }
 
 type YamlListOutputFormat struct{}
@@ -65,6 +99,20 @@ func (f *YamlListOutputFormat) Render(list map[string]string, keySuffix string)
 	return "", log.Errf(OutputFormatRenderErr, "Could not render YAML output for list %+v", list)
 }
 
+func (f *YamlListOutputFormat) RenderDiff(diff []DiffEntry) (string, error) {
+	entries := yaml.MapSlice{}
+	for _, e := range diff {
+		entries = append(entries, yaml.MapItem{Key: e.Name, Value: e.toMap()})
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	if data, err := yaml.Marshal(entries); err == nil {
+		return string(data), nil
+	}
+	return "", log.Errf(OutputFormatRenderErr, "Could not render YAML output for diff %+v", diff)
+}
+
 type PropertiesListOutputFormat struct{}
 
 func (f *PropertiesListOutputFormat) Render(list map[string]string, keySuffix string) (string, error) {
@@ -83,3 +131,13 @@ func (f *PropertiesListOutputFormat) Render(list map[string]string, keySuffix st
 	}
 	return strings.TrimSuffix(data, "\n"), nil
 }
+
+func (f *PropertiesListOutputFormat) RenderDiff(diff []DiffEntry) (string, error) {
+	data := ""
+	for _, e := range diff {
+		for _, item := range e.toMap() {
+			data += fmt.Sprintf("%s.%s=%s\n", e.Name, item.Key, item.Value)
+		}
+	}
+	return strings.TrimSuffix(data, "\n"), nil
+}
@@ -52,6 +52,31 @@ app3.version=3.0.0`
 	r.Equal(expected, output)
 }
 
+func (suite *OutputFormatSuite) TestRenderDiff() {
+	diff := []DiffEntry{
+		{Name: "app1", Change: "upgraded", From: "1.0.0", To: "1.1.0"},
+		{Name: "app2", Change: "removed", From: "2.0.0"},
+	}
+	r := suite.Require()
+	output, err := RenderDiff("yaml", diff)
+	r.Nil(err)
+	r.Equal(`app1:
+  change: upgraded
+  from: 1.0.0
+  to: 1.1.0
+app2:
+  change: removed
+  from: 2.0.0
+`, output)
+	output, err = RenderDiff("properties", diff)
+	r.Nil(err)
+	r.Equal(`app1.change=upgraded
+app1.from=1.0.0
+app1.to=1.1.0
+app2.change=removed
+app2.from=2.0.0`, output)
+}
+
 func (suite *OutputFormatSuite) TearDownSuite() {
 	filet.CleanUp(suite.T())
 }
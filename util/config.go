@@ -33,6 +33,41 @@ func newAuthType(value string) (AuthType, error) {
 	return 0, UnsupportedGitAuthTypeErr
 }
 
+type SigningType int
+
+const (
+	SshSigning SigningType = iota + 1
+	OpenPgpSigning
+)
+
+var UnsupportedSigningTypeErr = errors.New("unsupported commit signing type")
+
+func newSigningType(value string) (SigningType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "ssh":
+		return SshSigning, nil
+	case "openpgp", "gpg":
+		return OpenPgpSigning, nil
+	}
+	return 0, UnsupportedSigningTypeErr
+}
+
+func (t SigningType) String() string {
+	return [...]string{"ssh", "openpgp"}[t-1]
+}
+
+// SigningConfig The key used to sign commits and the signers allowed when verifying them, commits are not signed when
+// no Type is set
+type SigningConfig struct {
+	Type SigningType
+	//KeyFile a private SSH key or an armored OpenPGP private key
+	KeyFile string
+	//KeyPass the passphrase of the key, optionally base64 encoded
+	KeyPass string
+	//AllowedSignersFile the signers trusted by gosh verify commits, in the git allowed signers format
+	AllowedSignersFile string
+}
+
 type AuthConfig interface {
 	Type() AuthType
 }
@@ -78,8 +113,65 @@ func newGitAuthConfig(authConfig authConfigDef) (AuthConfig, error) {
 
 type GoshConfig struct {
 	Auth                 AuthConfig
+	Signing              SigningConfig
+	Repository           RepositoryConfig
 	Output               OutputConfig
 	ArtifactRepositories map[string]map[string]string
+	Stages               StagesConfig
+	Releases             ReleasesConfig
+	Versions             VersionsConfig
+	Commits              CommitsConfig
+}
+
+const (
+	DefaultCommitterName  = "gosh"
+	DefaultCommitterEmail = "gosh@github.com"
+)
+
+type CommitsConfig struct {
+	//Author the author of commits, defaults to the user in the git config or the committer when none is configured
+	Author SignatureConfig
+	//Committer the committer of commits and tagger of release tags, defaults to gosh <gosh@github.com>
+	Committer SignatureConfig
+	//MessageTemplate a Go template for commit messages, with the fields .Summary, .App, .Version, .Stage, .Release, .Target and .BuildUrl
+	MessageTemplate string `mapstructure:"message_template"`
+	//BuildUrl the URL of the CI build making the changes, detected from the CI environment when not set
+	BuildUrl string `mapstructure:"build_url"`
+}
+
+type SignatureConfig struct {
+	Name  string
+	Email string
+}
+
+type RepositoryConfig struct {
+	//Branch the branch of the deployment repository to clone, pull and push, the default branch of the remote when empty
+	Branch string
+	//Depth the number of commits to clone and fetch, the full history when 0
+	Depth int
+	//Sparse only checks out inventory/classes when cloning
+	Sparse bool
+	//Offline never pulls or accesses the remote otherwise, so gosh works without network
+	Offline bool
+}
+
+const DefaultVersionScheme = "semver"
+
+type VersionsConfig struct {
+	//Scheme the version scheme of apps that do not set one themselves: semver, calendar or free-form
+	Scheme string
+}
+
+const DefaultReleaseTagFormat = "{{.Type}}/{{.Name}}"
+
+type ReleasesConfig struct {
+	//TagFormat a Go template for the git tag name of finalized releases, with the fields .Type and .Name
+	TagFormat string `mapstructure:"tag_format"`
+}
+
+type StagesConfig struct {
+	//Pipeline the ordered list of stages a version has to go through, empty if the order is not enforced
+	Pipeline []string
 }
 
 type OutputConfig struct {
@@ -105,7 +197,13 @@ func InitializeConfig() {
 	loadGlobalConfigAndMerge(vpr, v)
 	initOutputConfig(vpr)
 	initAuthConfig(vpr)
+	initSigningConfig(vpr)
+	initRepositoryConfig(vpr)
 	initArtifactRepositoryConfig(vpr)
+	initStagesConfig(vpr)
+	initReleasesConfig(vpr)
+	initVersionsConfig(vpr)
+	initCommitsConfig(vpr)
 	log.Debugf("Loaded configuration %+v", Config)
 }
 
@@ -184,6 +282,31 @@ func initAuthConfig(vpr *viper.Viper) {
 	}
 }
 
+func initSigningConfig(vpr *viper.Viper) {
+	Config.Signing = SigningConfig{
+		KeyFile:            os.ExpandEnv(vpr.GetString("auth.signing.key_file")),
+		KeyPass:            vpr.GetString("auth.signing.key_pass"),
+		AllowedSignersFile: os.ExpandEnv(vpr.GetString("auth.signing.allowed_signers_file")),
+	}
+	if vpr.IsSet("auth.signing.type") {
+		t, err := newSigningType(vpr.GetString("auth.signing.type"))
+		if err != nil {
+			log.Fatal(err, "Invalid commit signing type %s, use ssh or openpgp", vpr.GetString("auth.signing.type"))
+		}
+		Config.Signing.Type = t
+		log.Debugf("Signing commits with %s key %s", t, Config.Signing.KeyFile)
+	}
+}
+
+func initRepositoryConfig(vpr *viper.Viper) {
+	Config.Repository = RepositoryConfig{
+		Branch:  strings.TrimSpace(vpr.GetString("repository.branch")),
+		Depth:   vpr.GetInt("repository.depth"),
+		Sparse:  vpr.GetBool("repository.sparse"),
+		Offline: vpr.GetBool("repository.offline"),
+	}
+}
+
 func initOutputConfig(vpr *viper.Viper) {
 	Config.Output = OutputConfig{
 		DefaultFormat:      "yaml",
@@ -199,3 +322,79 @@ func initOutputConfig(vpr *viper.Viper) {
 		Config.Output = outputConfig
 	}
 }
+
+func initStagesConfig(vpr *viper.Viper) {
+	Config.Stages = StagesConfig{
+		Pipeline: []string{},
+	}
+	if vpr.IsSet("stages.pipeline") {
+		//ENV variables are a single comma separated string, config files contain a list
+		for _, value := range vpr.GetStringSlice("stages.pipeline") {
+			for _, stage := range strings.Split(value, ",") {
+				if stage = strings.ToLower(strings.TrimSpace(stage)); stage != "" {
+					Config.Stages.Pipeline = append(Config.Stages.Pipeline, stage)
+				}
+			}
+		}
+	}
+}
+
+func initReleasesConfig(vpr *viper.Viper) {
+	Config.Releases = ReleasesConfig{
+		TagFormat: DefaultReleaseTagFormat,
+	}
+	if vpr.IsSet("releases.tag_format") {
+		Config.Releases.TagFormat = vpr.GetString("releases.tag_format")
+	}
+}
+
+func initVersionsConfig(vpr *viper.Viper) {
+	Config.Versions = VersionsConfig{
+		Scheme: DefaultVersionScheme,
+	}
+	if vpr.IsSet("versions.scheme") {
+		Config.Versions.Scheme = strings.ToLower(strings.TrimSpace(vpr.GetString("versions.scheme")))
+	}
+}
+
+func initCommitsConfig(vpr *viper.Viper) {
+	Config.Commits = CommitsConfig{
+		Author: SignatureConfig{
+			Name:  vpr.GetString("commits.author.name"),
+			Email: vpr.GetString("commits.author.email"),
+		},
+		Committer: SignatureConfig{
+			Name:  DefaultCommitterName,
+			Email: DefaultCommitterEmail,
+		},
+		MessageTemplate: vpr.GetString("commits.message_template"),
+		BuildUrl:        vpr.GetString("commits.build_url"),
+	}
+	if vpr.IsSet("commits.committer.name") {
+		Config.Commits.Committer.Name = vpr.GetString("commits.committer.name")
+	}
+	if vpr.IsSet("commits.committer.email") {
+		Config.Commits.Committer.Email = vpr.GetString("commits.committer.email")
+	}
+	if Config.Commits.BuildUrl == "" {
+		Config.Commits.BuildUrl = detectBuildUrl()
+	}
+}
+
+// detectBuildUrl Returns the URL of the current CI build for Jenkins, GitLab CI and GitHub Actions, empty if unknown
+func detectBuildUrl() string {
+	if url := os.Getenv("BUILD_URL"); url != "" {
+		return url
+	}
+	if url := os.Getenv("CI_JOB_URL"); url != "" {
+		return url
+	}
+	if os.Getenv("GITHUB_RUN_ID") != "" && os.Getenv("GITHUB_REPOSITORY") != "" {
+		server := os.Getenv("GITHUB_SERVER_URL")
+		if server == "" {
+			server = "https://github.com"
+		}
+		return server + "/" + os.Getenv("GITHUB_REPOSITORY") + "/actions/runs/" + os.Getenv("GITHUB_RUN_ID")
+	}
+	return ""
+}
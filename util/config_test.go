@@ -115,10 +115,124 @@ func (suite *ConfigTestSuite) TestInitializeConfig_SshAuth_Env() {
 	r.Equal("private-key-pass", auth.PrivateKeyPass)
 }
 
+func (suite *ConfigTestSuite) TestInitializeSigningConfig() {
+	InitializeConfig()
+	r := suite.Require()
+	r.Equal(SigningConfig{}, Config.Signing)
+
+	contents := []byte(`
+Auth:
+  Type: ssh
+  Private_Key_File: private-key-file
+  Signing:
+    Type: openpgp
+    Key_File: signing-key-file
+    Allowed_Signers_File: allowed-signers
+`)
+	r.Nil(os.WriteFile(filepath.Join(suite.homedir, ".gosh", "config.yml"), contents, 0644))
+	_ = os.Setenv("GOSH_AUTH_SIGNING_KEY_PASS", "signing-key-pass")
+	InitializeConfig()
+	r.Equal(SigningConfig{Type: OpenPgpSigning, KeyFile: "signing-key-file", KeyPass: "signing-key-pass", AllowedSignersFile: "allowed-signers"}, Config.Signing)
+
+	_ = os.Setenv("GOSH_AUTH_SIGNING_TYPE", "ssh")
+	InitializeConfig()
+	r.Equal(SshSigning, Config.Signing.Type)
+}
+
+func (suite *ConfigTestSuite) TestInitializeRepositoryConfig() {
+	InitializeConfig()
+	r := suite.Require()
+	r.Equal(RepositoryConfig{}, Config.Repository)
+
+	contents := []byte(`
+Repository:
+  Branch: staging
+  Depth: 1
+`)
+	r.Nil(os.WriteFile(filepath.Join(suite.homedir, ".gosh", "config.yml"), contents, 0644))
+	InitializeConfig()
+	r.Equal(RepositoryConfig{Branch: "staging", Depth: 1}, Config.Repository)
+
+	_ = os.Setenv("GOSH_REPOSITORY_BRANCH", "main")
+	_ = os.Setenv("GOSH_REPOSITORY_SPARSE", "true")
+	_ = os.Setenv("GOSH_REPOSITORY_OFFLINE", "true")
+	InitializeConfig()
+	r.Equal(RepositoryConfig{Branch: "main", Depth: 1, Sparse: true, Offline: true}, Config.Repository)
+}
+
+func (suite *ConfigTestSuite) TestInitializeStagesConfig_ConfigFile() {
+	contents := []byte(`
+Stages:
+  Pipeline:
+    - Tested
+    - published
+    - released
+`)
+	r := suite.Require()
+	err := os.WriteFile(filepath.Join(suite.homedir, ".gosh", "config.yml"), contents, 0644)
+	if err != nil {
+		r.Fail("unable to init test, cannot create ~/.gosh/config.yml file")
+	}
+	InitializeConfig()
+	r.Equal([]string{"tested", "published", "released"}, Config.Stages.Pipeline)
+}
+
+func (suite *ConfigTestSuite) TestInitializeStagesConfig_Env() {
+	_ = os.Setenv("GOSH_STAGES_PIPELINE", "tested, published,released")
+
+	InitializeConfig()
+	r := suite.Require()
+	r.Equal([]string{"tested", "published", "released"}, Config.Stages.Pipeline)
+}
+
+func (suite *ConfigTestSuite) TestInitializeReleasesConfig() {
+	InitializeConfig()
+	r := suite.Require()
+	r.Equal(DefaultReleaseTagFormat, Config.Releases.TagFormat)
+
+	_ = os.Setenv("GOSH_RELEASES_TAG_FORMAT", "release-{{.Name}}")
+	InitializeConfig()
+	r.Equal("release-{{.Name}}", Config.Releases.TagFormat)
+}
+
+func (suite *ConfigTestSuite) TestInitializeVersionsConfig() {
+	InitializeConfig()
+	r := suite.Require()
+	r.Equal(DefaultVersionScheme, Config.Versions.Scheme)
+
+	_ = os.Setenv("GOSH_VERSIONS_SCHEME", "Calendar")
+	defer os.Unsetenv("GOSH_VERSIONS_SCHEME")
+	InitializeConfig()
+	r.Equal("calendar", Config.Versions.Scheme)
+}
+
 func (suite *ConfigTestSuite) TearDownSuite() {
 	filet.CleanUp(suite.T())
 }
 
+func (suite *ConfigTestSuite) TestInitializeCommitsConfig() {
+	for _, env := range []string{"BUILD_URL", "CI_JOB_URL", "GITHUB_RUN_ID"} {
+		defer os.Setenv(env, os.Getenv(env))
+		_ = os.Unsetenv(env)
+	}
+	InitializeConfig()
+	r := suite.Require()
+	r.Equal(SignatureConfig{Name: DefaultCommitterName, Email: DefaultCommitterEmail}, Config.Commits.Committer)
+	r.Equal(SignatureConfig{}, Config.Commits.Author)
+	r.Equal("", Config.Commits.BuildUrl)
+
+	_ = os.Setenv("GOSH_COMMITS_AUTHOR_NAME", "ci")
+	_ = os.Setenv("GOSH_COMMITS_AUTHOR_EMAIL", "ci@example.com")
+	_ = os.Setenv("GITHUB_RUN_ID", "42")
+	_ = os.Setenv("GITHUB_REPOSITORY", "acme/deployments")
+	defer os.Unsetenv("GOSH_COMMITS_AUTHOR_NAME")
+	defer os.Unsetenv("GOSH_COMMITS_AUTHOR_EMAIL")
+	defer os.Unsetenv("GITHUB_REPOSITORY")
+	InitializeConfig()
+	r.Equal(SignatureConfig{Name: "ci", Email: "ci@example.com"}, Config.Commits.Author)
+	r.Equal("https://github.com/acme/deployments/actions/runs/42", Config.Commits.BuildUrl)
+}
+
 func TestConfigTestSuite(t *testing.T) {
 	suite.Run(t, new(ConfigTestSuite))
 }